@@ -0,0 +1,62 @@
+// Package cmd builds ESC/POS command sequences.
+//
+// Every constructor returns the exact bytes of one command as described in
+// the Epson ESC/POS reference. Constructors with parameters validate them
+// and return an error instead of bytes the printer would misread.
+package cmd
+
+import (
+	"fmt"
+
+	utilInternal "github.com/AlexStarov/escpos-GoLang-lib/util"
+)
+
+// Control codes that start ESC/POS commands.
+const (
+	HT  = 0x09
+	LF  = 0x0a
+	FF  = 0x0c
+	CR  = 0x0d
+	CAN = 0x18
+	DLE = 0x10
+	EOT = 0x04
+	ENQ = 0x05
+	DC4 = 0x14
+	ESC = 0x1b
+	FS  = 0x1c
+	GS  = 0x1d
+)
+
+func seq(b ...byte) []byte {
+	return b
+}
+
+func boolByte(v bool) byte {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+// inRange reports an error unless lo <= n <= hi.
+func inRange(name string, n, lo, hi int) error {
+	if n < lo || n > hi {
+		return fmt.Errorf("cmd: %s %d out of range %d–%d", name, n, lo, hi)
+	}
+	return nil
+}
+
+// oneOf reports an error unless n is one of the allowed values.
+func oneOf(name string, n byte, allowed ...byte) error {
+	for _, a := range allowed {
+		if n == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("cmd: invalid %s %d", name, n)
+}
+
+// lowHigh encodes n as nL nH.
+func lowHigh(n int) []byte {
+	return utilInternal.IntLowHigh(n, 2)
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// result is what a command builder returned.
+type result struct {
+	b   []byte
+	err error
+}
+
+func res(b []byte, err error) result {
+	return result{b, err}
+}
+
+// ok is the result of a builder that cannot fail.
+func ok(b []byte) result {
+	return result{b, nil}
+}
+
+// builderTest is one call of a command builder: the exact bytes it must
+// return, or a fragment of the error it must fail with.
+type builderTest struct {
+	name string
+	got  result
+	want []byte
+	err  string
+}
+
+func runBuilderTests(t *testing.T, tests []builderTest) {
+	t.Helper()
+	for _, tt := range tests {
+		switch {
+		case tt.err != "":
+			if tt.got.err == nil || !strings.Contains(tt.got.err.Error(), tt.err) {
+				t.Errorf("%s: error %v, want one containing %q", tt.name, tt.got.err, tt.err)
+			}
+			if tt.got.b != nil {
+				t.Errorf("%s: bytes % x returned with the error", tt.name, tt.got.b)
+			}
+		case tt.got.err != nil:
+			t.Errorf("%s: unexpected error %v", tt.name, tt.got.err)
+		case !bytes.Equal(tt.got.b, tt.want):
+			t.Errorf("%s: % x, want % x", tt.name, tt.got.b, tt.want)
+		}
+	}
+}
+
+func TestLowHigh(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"0", ok(lowHigh(0)), []byte{0, 0}, ""},
+		{"255", ok(lowHigh(255)), []byte{0xff, 0}, ""},
+		{"256", ok(lowHigh(256)), []byte{0, 1}, ""},
+		{"65535", ok(lowHigh(65535)), []byte{0xff, 0xff}, ""},
+	})
+}
@@ -0,0 +1,38 @@
+package cmd
+
+// Status kinds of TransmitStatus.
+const (
+	StatusPrinter = 1 // printer status
+	StatusOffline = 2 // offline cause
+	StatusError   = 3 // error cause
+	StatusPaper   = 4 // roll paper sensor
+)
+
+// TransmitStatus is DLE EOT n: real-time status request.
+func TransmitStatus(n byte) ([]byte, error) {
+	if err := oneOf("status kind", n, StatusPrinter, StatusOffline, StatusError, StatusPaper); err != nil {
+		return nil, err
+	}
+	return seq(DLE, EOT, n), nil
+}
+
+// RealtimeRequest is DLE ENQ n: 1 recover and restart, 2 recover and clear
+// the buffers.
+func RealtimeRequest(n byte) ([]byte, error) {
+	if err := oneOf("real-time request", n, 1, 2); err != nil {
+		return nil, err
+	}
+	return seq(DLE, ENQ, n), nil
+}
+
+// RealtimePulse is DLE DC4 1 m t: pulse drawer pin 2 (m 0) or 5 (m 1)
+// for t×100 ms, t 1–8.
+func RealtimePulse(m, t byte) ([]byte, error) {
+	if err := oneOf("drawer pin", m, 0, 1); err != nil {
+		return nil, err
+	}
+	if err := inRange("pulse time", int(t), 1, 8); err != nil {
+		return nil, err
+	}
+	return seq(DLE, DC4, 1, m, t), nil
+}
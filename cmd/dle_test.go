@@ -0,0 +1,30 @@
+package cmd
+
+import "testing"
+
+func TestTransmitStatus(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"printer", res(TransmitStatus(StatusPrinter)), []byte{DLE, EOT, 1}, ""},
+		{"paper", res(TransmitStatus(StatusPaper)), []byte{DLE, EOT, 4}, ""},
+		{"0", res(TransmitStatus(0)), nil, "invalid status kind"},
+		{"5", res(TransmitStatus(5)), nil, "invalid status kind"},
+	})
+}
+
+func TestRealtimeRequest(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"restart", res(RealtimeRequest(1)), []byte{DLE, ENQ, 1}, ""},
+		{"clear", res(RealtimeRequest(2)), []byte{DLE, ENQ, 2}, ""},
+		{"3", res(RealtimeRequest(3)), nil, "invalid real-time request"},
+	})
+}
+
+func TestRealtimePulse(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"pin 2", res(RealtimePulse(0, 1)), []byte{DLE, DC4, 1, 0, 1}, ""},
+		{"pin 5", res(RealtimePulse(1, 8)), []byte{DLE, DC4, 1, 1, 8}, ""},
+		{"pin 3", res(RealtimePulse(2, 1)), nil, "invalid drawer pin"},
+		{"t 0", res(RealtimePulse(0, 0)), nil, "pulse time 0 out of range"},
+		{"t 9", res(RealtimePulse(0, 9)), nil, "pulse time 9 out of range"},
+	})
+}
@@ -0,0 +1,224 @@
+package cmd
+
+import "fmt"
+
+// Init is ESC @: clear the print buffer and reset all settings.
+func Init() []byte {
+	return seq(ESC, '@')
+}
+
+// LineFeed is LF: print the buffer and feed one line.
+func LineFeed() []byte {
+	return seq(LF)
+}
+
+// PrintAndFeedLines is ESC d n: print the buffer and feed n lines.
+func PrintAndFeedLines(n int) ([]byte, error) {
+	if err := inRange("feed lines", n, 0, 255); err != nil {
+		return nil, err
+	}
+	return seq(ESC, 'd', byte(n)), nil
+}
+
+// PrintAndFeedDots is ESC J n: print the buffer and feed n motion units.
+func PrintAndFeedDots(n int) ([]byte, error) {
+	if err := inRange("feed units", n, 0, 255); err != nil {
+		return nil, err
+	}
+	return seq(ESC, 'J', byte(n)), nil
+}
+
+// Print mode bits of SelectPrintMode.
+const (
+	ModeFontB        = 0x01
+	ModeEmphasized   = 0x08
+	ModeDoubleHeight = 0x10
+	ModeDoubleWidth  = 0x20
+	ModeUnderline    = 0x80
+)
+
+// SelectPrintMode is ESC ! n with n a combination of the Mode* bits.
+func SelectPrintMode(n byte) ([]byte, error) {
+	if n&^(ModeFontB|ModeEmphasized|ModeDoubleHeight|ModeDoubleWidth|ModeUnderline) != 0 {
+		return nil, fmt.Errorf("cmd: invalid print mode 0x%02x", n)
+	}
+	return seq(ESC, '!', n), nil
+}
+
+// RightSideSpacing is ESC SP n: extra dots to the right of each character.
+func RightSideSpacing(n int) ([]byte, error) {
+	if err := inRange("character spacing", n, 0, 255); err != nil {
+		return nil, err
+	}
+	return seq(ESC, ' ', byte(n)), nil
+}
+
+// AbsolutePosition is ESC $ nL nH: move to n motion units from the line start.
+func AbsolutePosition(n int) ([]byte, error) {
+	if err := inRange("absolute position", n, 0, 65535); err != nil {
+		return nil, err
+	}
+	return append(seq(ESC, '$'), lowHigh(n)...), nil
+}
+
+// RelativePosition is ESC \ nL nH: move n motion units, negative to the left.
+func RelativePosition(n int) ([]byte, error) {
+	if err := inRange("relative position", n, -32768, 32767); err != nil {
+		return nil, err
+	}
+	return append(seq(ESC, '\\'), lowHigh(n&0xffff)...), nil
+}
+
+// Underline is ESC - n: 0 off, 1 one dot thick, 2 two dots thick.
+func Underline(n byte) ([]byte, error) {
+	if err := oneOf("underline mode", n, 0, 1, 2); err != nil {
+		return nil, err
+	}
+	return seq(ESC, '-', n), nil
+}
+
+// DefaultLineSpacing is ESC 2.
+func DefaultLineSpacing() []byte {
+	return seq(ESC, '2')
+}
+
+// LineSpacing is ESC 3 n: line spacing of n motion units.
+func LineSpacing(n int) ([]byte, error) {
+	if err := inRange("line spacing", n, 0, 255); err != nil {
+		return nil, err
+	}
+	return seq(ESC, '3', byte(n)), nil
+}
+
+// Emphasize is ESC E n (bold).
+func Emphasize(on bool) []byte {
+	return seq(ESC, 'E', boolByte(on))
+}
+
+// DoubleStrike is ESC G n.
+func DoubleStrike(on bool) []byte {
+	return seq(ESC, 'G', boolByte(on))
+}
+
+// Fonts of SelectFont.
+const (
+	FontA = 0
+	FontB = 1
+	FontC = 2
+)
+
+// SelectFont is ESC M n.
+func SelectFont(n byte) ([]byte, error) {
+	if err := oneOf("font", n, FontA, FontB, FontC, 3, 4); err != nil {
+		return nil, err
+	}
+	return seq(ESC, 'M', n), nil
+}
+
+// InternationalCharset is ESC R n: 0–17, 66–75 and 82.
+func InternationalCharset(n byte) ([]byte, error) {
+	if n > 17 && (n < 66 || n > 75) && n != 82 {
+		return nil, fmt.Errorf("cmd: invalid international character set %d", n)
+	}
+	return seq(ESC, 'R', n), nil
+}
+
+// Rotate90 is ESC V n: 0 off, 1 on, 2 on with 1.5-dot character spacing.
+func Rotate90(n byte) ([]byte, error) {
+	if err := oneOf("rotate mode", n, 0, 1, 2); err != nil {
+		return nil, err
+	}
+	return seq(ESC, 'V', n), nil
+}
+
+// Justifications of Justify.
+const (
+	JustifyLeft   = 0
+	JustifyCenter = 1
+	JustifyRight  = 2
+)
+
+// Justify is ESC a n.
+func Justify(n byte) ([]byte, error) {
+	if err := oneOf("justification", n, JustifyLeft, JustifyCenter, JustifyRight); err != nil {
+		return nil, err
+	}
+	return seq(ESC, 'a', n), nil
+}
+
+// UpsideDown is ESC { n.
+func UpsideDown(on bool) []byte {
+	return seq(ESC, '{', boolByte(on))
+}
+
+// CodeTable is ESC t n: select the character code table (code page).
+func CodeTable(n byte) []byte {
+	return seq(ESC, 't', n)
+}
+
+// Pulse is ESC p m t1 t2: drive drawer kick-out connector pin 2 (m 0) or
+// pin 5 (m 1) on for t1×2 ms and off for t2×2 ms.
+func Pulse(m, t1, t2 byte) ([]byte, error) {
+	if err := oneOf("drawer pin", m, 0, 1, 48, 49); err != nil {
+		return nil, err
+	}
+	return seq(ESC, 'p', m, t1, t2), nil
+}
+
+// PanelButtons is ESC c 5 n.
+func PanelButtons(enabled bool) []byte {
+	return seq(ESC, 'c', '5', boolByte(!enabled))
+}
+
+// PageMode is ESC L.
+func PageMode() []byte {
+	return seq(ESC, 'L')
+}
+
+// StandardMode is ESC S.
+func StandardMode() []byte {
+	return seq(ESC, 'S')
+}
+
+// UserDefinedChars is ESC % n: use downloaded characters instead of ROM.
+func UserDefinedChars(on bool) []byte {
+	return seq(ESC, '%', boolByte(on))
+}
+
+// UserChar is one downloaded character for DefineUserChars: Width columns
+// of y bytes each, top to bottom then left to right.
+type UserChar struct {
+	Width byte
+	Data  []byte
+}
+
+// DefineUserChars is ESC & y c1 c2 [x d1...d(y×x)]k: define characters c1
+// to c2 (32–126), y bytes tall.
+func DefineUserChars(y, c1, c2 byte, chars []UserChar) ([]byte, error) {
+	if err := inRange("user character height", int(y), 1, 3); err != nil {
+		return nil, err
+	}
+	if c1 < 32 || c2 > 126 || c1 > c2 {
+		return nil, fmt.Errorf("cmd: invalid user character range %d–%d", c1, c2)
+	}
+	if len(chars) != int(c2-c1)+1 {
+		return nil, fmt.Errorf("cmd: %d user characters for range %d–%d", len(chars), c1, c2)
+	}
+	out := seq(ESC, '&', y, c1, c2)
+	for i, c := range chars {
+		if len(c.Data) != int(y)*int(c.Width) {
+			return nil, fmt.Errorf("cmd: user character %d has %d bytes, want %d", int(c1)+i, len(c.Data), int(y)*int(c.Width))
+		}
+		out = append(out, c.Width)
+		out = append(out, c.Data...)
+	}
+	return out, nil
+}
+
+// CancelUserChar is ESC ? n.
+func CancelUserChar(n byte) ([]byte, error) {
+	if err := inRange("user character", int(n), 32, 126); err != nil {
+		return nil, err
+	}
+	return seq(ESC, '?', n), nil
+}
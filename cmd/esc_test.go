@@ -0,0 +1,167 @@
+package cmd
+
+import "testing"
+
+func TestEscFixed(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"Init", ok(Init()), []byte{ESC, '@'}, ""},
+		{"LineFeed", ok(LineFeed()), []byte{LF}, ""},
+		{"DefaultLineSpacing", ok(DefaultLineSpacing()), []byte{ESC, '2'}, ""},
+		{"Emphasize on", ok(Emphasize(true)), []byte{ESC, 'E', 1}, ""},
+		{"Emphasize off", ok(Emphasize(false)), []byte{ESC, 'E', 0}, ""},
+		{"DoubleStrike on", ok(DoubleStrike(true)), []byte{ESC, 'G', 1}, ""},
+		{"DoubleStrike off", ok(DoubleStrike(false)), []byte{ESC, 'G', 0}, ""},
+		{"UpsideDown on", ok(UpsideDown(true)), []byte{ESC, '{', 1}, ""},
+		{"CodeTable", ok(CodeTable(17)), []byte{ESC, 't', 17}, ""},
+		{"PanelButtons enabled", ok(PanelButtons(true)), []byte{ESC, 'c', '5', 0}, ""},
+		{"PanelButtons disabled", ok(PanelButtons(false)), []byte{ESC, 'c', '5', 1}, ""},
+		{"PageMode", ok(PageMode()), []byte{ESC, 'L'}, ""},
+		{"StandardMode", ok(StandardMode()), []byte{ESC, 'S'}, ""},
+		{"UserDefinedChars on", ok(UserDefinedChars(true)), []byte{ESC, '%', 1}, ""},
+		{"UserDefinedChars off", ok(UserDefinedChars(false)), []byte{ESC, '%', 0}, ""},
+	})
+}
+
+func TestPrintAndFeedLines(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"0", res(PrintAndFeedLines(0)), []byte{ESC, 'd', 0}, ""},
+		{"255", res(PrintAndFeedLines(255)), []byte{ESC, 'd', 255}, ""},
+		{"-1", res(PrintAndFeedLines(-1)), nil, "out of range"},
+		{"256", res(PrintAndFeedLines(256)), nil, "out of range"},
+	})
+}
+
+func TestPrintAndFeedDots(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"30", res(PrintAndFeedDots(30)), []byte{ESC, 'J', 30}, ""},
+		{"-1", res(PrintAndFeedDots(-1)), nil, "out of range"},
+		{"256", res(PrintAndFeedDots(256)), nil, "out of range"},
+	})
+}
+
+func TestSelectPrintMode(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"none", res(SelectPrintMode(0)), []byte{ESC, '!', 0}, ""},
+		{"all", res(SelectPrintMode(ModeFontB | ModeEmphasized | ModeDoubleHeight | ModeDoubleWidth | ModeUnderline)), []byte{ESC, '!', 0xb9}, ""},
+		{"bit 1", res(SelectPrintMode(0x02)), nil, "invalid print mode"},
+		{"bit 6", res(SelectPrintMode(0x40)), nil, "invalid print mode"},
+	})
+}
+
+func TestRightSideSpacing(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"0", res(RightSideSpacing(0)), []byte{ESC, ' ', 0}, ""},
+		{"255", res(RightSideSpacing(255)), []byte{ESC, ' ', 255}, ""},
+		{"256", res(RightSideSpacing(256)), nil, "out of range"},
+	})
+}
+
+func TestAbsolutePosition(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"0", res(AbsolutePosition(0)), []byte{ESC, '$', 0, 0}, ""},
+		{"300", res(AbsolutePosition(300)), []byte{ESC, '$', 0x2c, 0x01}, ""},
+		{"-1", res(AbsolutePosition(-1)), nil, "out of range"},
+		{"65536", res(AbsolutePosition(65536)), nil, "out of range"},
+	})
+}
+
+func TestRelativePosition(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"right", res(RelativePosition(10)), []byte{ESC, '\\', 10, 0}, ""},
+		{"left", res(RelativePosition(-1)), []byte{ESC, '\\', 0xff, 0xff}, ""},
+		{"min", res(RelativePosition(-32768)), []byte{ESC, '\\', 0, 0x80}, ""},
+		{"too far left", res(RelativePosition(-32769)), nil, "out of range"},
+		{"too far right", res(RelativePosition(32768)), nil, "out of range"},
+	})
+}
+
+func TestUnderline(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"off", res(Underline(0)), []byte{ESC, '-', 0}, ""},
+		{"2 dots", res(Underline(2)), []byte{ESC, '-', 2}, ""},
+		{"3", res(Underline(3)), nil, "invalid underline mode"},
+	})
+}
+
+func TestLineSpacing(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"30", res(LineSpacing(30)), []byte{ESC, '3', 30}, ""},
+		{"256", res(LineSpacing(256)), nil, "out of range"},
+	})
+}
+
+func TestSelectFont(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"A", res(SelectFont(FontA)), []byte{ESC, 'M', 0}, ""},
+		{"B", res(SelectFont(FontB)), []byte{ESC, 'M', 1}, ""},
+		{"C", res(SelectFont(FontC)), []byte{ESC, 'M', 2}, ""},
+		{"5", res(SelectFont(5)), nil, "invalid font"},
+	})
+}
+
+func TestInternationalCharset(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"USA", res(InternationalCharset(0)), []byte{ESC, 'R', 0}, ""},
+		{"17", res(InternationalCharset(17)), []byte{ESC, 'R', 17}, ""},
+		{"India", res(InternationalCharset(66)), []byte{ESC, 'R', 66}, ""},
+		{"75", res(InternationalCharset(75)), []byte{ESC, 'R', 75}, ""},
+		{"82", res(InternationalCharset(82)), []byte{ESC, 'R', 82}, ""},
+		{"18", res(InternationalCharset(18)), nil, "invalid international character set"},
+		{"65", res(InternationalCharset(65)), nil, "invalid international character set"},
+		{"76", res(InternationalCharset(76)), nil, "invalid international character set"},
+		{"255", res(InternationalCharset(255)), nil, "invalid international character set"},
+	})
+}
+
+func TestRotate90(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"off", res(Rotate90(0)), []byte{ESC, 'V', 0}, ""},
+		{"on", res(Rotate90(1)), []byte{ESC, 'V', 1}, ""},
+		{"1.5 dot", res(Rotate90(2)), []byte{ESC, 'V', 2}, ""},
+		{"3", res(Rotate90(3)), nil, "invalid rotate mode"},
+	})
+}
+
+func TestJustify(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"left", res(Justify(JustifyLeft)), []byte{ESC, 'a', 0}, ""},
+		{"right", res(Justify(JustifyRight)), []byte{ESC, 'a', 2}, ""},
+		{"3", res(Justify(3)), nil, "invalid justification"},
+	})
+}
+
+func TestPulse(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"pin 2", res(Pulse(0, 25, 250)), []byte{ESC, 'p', 0, 25, 250}, ""},
+		{"pin 5 ASCII", res(Pulse('1', 1, 1)), []byte{ESC, 'p', '1', 1, 1}, ""},
+		{"pin 3", res(Pulse(2, 1, 1)), nil, "invalid drawer pin"},
+	})
+}
+
+func TestDefineUserChars(t *testing.T) {
+	one := UserChar{Width: 2, Data: []byte{0x80, 0x01, 0x40, 0x02}}
+	runBuilderTests(t, []builderTest{
+		{"one", res(DefineUserChars(2, 'A', 'A', []UserChar{one})),
+			[]byte{ESC, '&', 2, 'A', 'A', 2, 0x80, 0x01, 0x40, 0x02}, ""},
+		{"two", res(DefineUserChars(1, 32, 33, []UserChar{{Width: 1, Data: []byte{0xff}}, {Width: 0}})),
+			[]byte{ESC, '&', 1, 32, 33, 1, 0xff, 0}, ""},
+		{"last", res(DefineUserChars(3, 126, 126, []UserChar{{Width: 1, Data: []byte{1, 2, 3}}})),
+			[]byte{ESC, '&', 3, 126, 126, 1, 1, 2, 3}, ""},
+		{"y 0", res(DefineUserChars(0, 'A', 'A', []UserChar{one})), nil, "height 0 out of range"},
+		{"y 4", res(DefineUserChars(4, 'A', 'A', []UserChar{one})), nil, "height 4 out of range"},
+		{"c1 31", res(DefineUserChars(2, 31, 31, []UserChar{one})), nil, "invalid user character range"},
+		{"c2 127", res(DefineUserChars(2, 126, 127, []UserChar{one, one})), nil, "invalid user character range"},
+		{"c1 > c2", res(DefineUserChars(2, 'B', 'A', []UserChar{one})), nil, "invalid user character range"},
+		{"too few", res(DefineUserChars(2, 'A', 'B', []UserChar{one})), nil, "1 user characters for range"},
+		{"short data", res(DefineUserChars(3, 'A', 'A', []UserChar{one})), nil, "has 4 bytes, want 6"},
+	})
+}
+
+func TestCancelUserChar(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"32", res(CancelUserChar(32)), []byte{ESC, '?', 32}, ""},
+		{"126", res(CancelUserChar(126)), []byte{ESC, '?', 126}, ""},
+		{"31", res(CancelUserChar(31)), nil, "out of range"},
+		{"127", res(CancelUserChar(127)), nil, "out of range"},
+	})
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import "fmt"
+
+// Kanji print mode bits of KanjiPrintMode.
+const (
+	KanjiDoubleWidth  = 0x04
+	KanjiDoubleHeight = 0x08
+	KanjiUnderline    = 0x80
+)
+
+// KanjiMode is FS &: print following double-byte codes as Kanji / CJK.
+func KanjiMode() []byte {
+	return seq(FS, '&')
+}
+
+// CancelKanjiMode is FS .
+func CancelKanjiMode() []byte {
+	return seq(FS, '.')
+}
+
+// KanjiPrintMode is FS ! n with n a combination of the Kanji* bits.
+func KanjiPrintMode(n byte) ([]byte, error) {
+	if n&^(KanjiDoubleWidth|KanjiDoubleHeight|KanjiUnderline) != 0 {
+		return nil, fmt.Errorf("cmd: invalid Kanji print mode 0x%02x", n)
+	}
+	return seq(FS, '!', n), nil
+}
+
+// Kanji code systems of KanjiCodeSystem.
+const (
+	KanjiJIS       = 0
+	KanjiShiftJIS  = 1
+	KanjiShiftJIS2 = 2 // Shift JIS-2004
+)
+
+// KanjiCodeSystem is FS C n.
+func KanjiCodeSystem(n byte) ([]byte, error) {
+	if err := oneOf("Kanji code system", n, KanjiJIS, KanjiShiftJIS, KanjiShiftJIS2, 48, 49, 50); err != nil {
+		return nil, err
+	}
+	return seq(FS, 'C', n), nil
+}
+
+// KanjiUnderlineMode is FS - n: 0 off, 1 or 2 dots thick.
+func KanjiUnderlineMode(n byte) ([]byte, error) {
+	if err := oneOf("Kanji underline mode", n, 0, 1, 2, 48, 49, 50); err != nil {
+		return nil, err
+	}
+	return seq(FS, '-', n), nil
+}
+
+// KanjiQuadruple is FS W n.
+func KanjiQuadruple(on bool) []byte {
+	return seq(FS, 'W', boolByte(on))
+}
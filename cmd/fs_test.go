@@ -0,0 +1,35 @@
+package cmd
+
+import "testing"
+
+func TestFsFixed(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"KanjiMode", ok(KanjiMode()), []byte{FS, '&'}, ""},
+		{"CancelKanjiMode", ok(CancelKanjiMode()), []byte{FS, '.'}, ""},
+		{"KanjiQuadruple on", ok(KanjiQuadruple(true)), []byte{FS, 'W', 1}, ""},
+		{"KanjiQuadruple off", ok(KanjiQuadruple(false)), []byte{FS, 'W', 0}, ""},
+	})
+}
+
+func TestKanjiPrintMode(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"none", res(KanjiPrintMode(0)), []byte{FS, '!', 0}, ""},
+		{"all", res(KanjiPrintMode(KanjiDoubleWidth | KanjiDoubleHeight | KanjiUnderline)), []byte{FS, '!', 0x8c}, ""},
+		{"bit 0", res(KanjiPrintMode(0x01)), nil, "invalid Kanji print mode"},
+	})
+}
+
+func TestKanjiCodeSystem(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"Shift-JIS", res(KanjiCodeSystem(KanjiShiftJIS)), []byte{FS, 'C', 1}, ""},
+		{"ASCII JIS", res(KanjiCodeSystem('0')), []byte{FS, 'C', '0'}, ""},
+		{"3", res(KanjiCodeSystem(3)), nil, "invalid Kanji code system"},
+	})
+}
+
+func TestKanjiUnderlineMode(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"1 dot", res(KanjiUnderlineMode(1)), []byte{FS, '-', 1}, ""},
+		{"3", res(KanjiUnderlineMode(3)), nil, "invalid Kanji underline mode"},
+	})
+}
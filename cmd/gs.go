@@ -0,0 +1,252 @@
+package cmd
+
+import "fmt"
+
+// CharSize is GS ! n: width and height magnification, 1–8 each.
+func CharSize(width, height int) ([]byte, error) {
+	if err := inRange("character width", width, 1, 8); err != nil {
+		return nil, err
+	}
+	if err := inRange("character height", height, 1, 8); err != nil {
+		return nil, err
+	}
+	return seq(GS, '!', byte((width-1)<<4|(height-1))), nil
+}
+
+// Reverse is GS B n (white on black).
+func Reverse(on bool) []byte {
+	return seq(GS, 'B', boolByte(on))
+}
+
+// Smooth is GS b n.
+func Smooth(on bool) []byte {
+	return seq(GS, 'b', boolByte(on))
+}
+
+// Cut modes of Cut and FeedAndCut.
+const (
+	CutFull    = 0
+	CutPartial = 1
+)
+
+// Cut is GS V m: cut the paper at the current position.
+func Cut(mode byte) ([]byte, error) {
+	if err := oneOf("cut mode", mode, CutFull, CutPartial); err != nil {
+		return nil, err
+	}
+	return seq(GS, 'V', mode), nil
+}
+
+// FeedAndCut is GS V m n: feed n motion units past the cutting position,
+// then cut.
+func FeedAndCut(mode byte, n byte) ([]byte, error) {
+	if err := oneOf("cut mode", mode, CutFull, CutPartial); err != nil {
+		return nil, err
+	}
+	return seq(GS, 'V', 65+mode, n), nil
+}
+
+// LeftMargin is GS L nL nH.
+func LeftMargin(n int) ([]byte, error) {
+	if err := inRange("left margin", n, 0, 65535); err != nil {
+		return nil, err
+	}
+	return append(seq(GS, 'L'), lowHigh(n)...), nil
+}
+
+// PrintAreaWidth is GS W nL nH.
+func PrintAreaWidth(n int) ([]byte, error) {
+	if err := inRange("print area width", n, 0, 65535); err != nil {
+		return nil, err
+	}
+	return append(seq(GS, 'W'), lowHigh(n)...), nil
+}
+
+// AbsoluteVerticalPosition is GS $ nL nH (page mode).
+func AbsoluteVerticalPosition(n int) ([]byte, error) {
+	if err := inRange("vertical position", n, 0, 65535); err != nil {
+		return nil, err
+	}
+	return append(seq(GS, '$'), lowHigh(n)...), nil
+}
+
+// TransmitPrinterID is GS I n: 1–3 for the ID bytes, 65–69 for the
+// information blocks.
+func TransmitPrinterID(n byte) ([]byte, error) {
+	if err := oneOf("printer ID", n, 1, 2, 3, 49, 50, 51, 65, 66, 67, 68, 69); err != nil {
+		return nil, err
+	}
+	return seq(GS, 'I', n), nil
+}
+
+// BarcodeHeight is GS h n, in dots.
+func BarcodeHeight(n int) ([]byte, error) {
+	if err := inRange("barcode height", n, 1, 255); err != nil {
+		return nil, err
+	}
+	return seq(GS, 'h', byte(n)), nil
+}
+
+// BarcodeWidth is GS w n: module width 2–6.
+func BarcodeWidth(n int) ([]byte, error) {
+	if err := inRange("barcode width", n, 2, 6); err != nil {
+		return nil, err
+	}
+	return seq(GS, 'w', byte(n)), nil
+}
+
+// HRI positions of HRIPosition.
+const (
+	HRINone  = 0
+	HRIAbove = 1
+	HRIBelow = 2
+	HRIBoth  = 3
+)
+
+// HRIPosition is GS H n: where to print the human readable barcode text.
+func HRIPosition(n byte) ([]byte, error) {
+	if err := oneOf("HRI position", n, HRINone, HRIAbove, HRIBelow, HRIBoth); err != nil {
+		return nil, err
+	}
+	return seq(GS, 'H', n), nil
+}
+
+// Barcode systems of Barcode (GS k function B).
+const (
+	BarcodeUPCA    = 65
+	BarcodeUPCE    = 66
+	BarcodeEAN13   = 67
+	BarcodeEAN8    = 68
+	BarcodeCODE39  = 69
+	BarcodeITF     = 70
+	BarcodeCODABAR = 71
+	BarcodeCODE93  = 72
+	BarcodeCODE128 = 73
+)
+
+// Barcode is GS k m n d1...dn.
+func Barcode(m byte, data []byte) ([]byte, error) {
+	if m < BarcodeUPCA || m > BarcodeCODE128 {
+		return nil, fmt.Errorf("cmd: invalid barcode system %d", m)
+	}
+	if err := inRange("barcode data length", len(data), 1, 255); err != nil {
+		return nil, err
+	}
+	return append(seq(GS, 'k', m, byte(len(data))), data...), nil
+}
+
+// RasterImage is GS v 0 m xL xH yL yH d1...dk: a raster bit image
+// widthBytes bytes wide and height dots tall. m selects the scaling, 0–3.
+func RasterImage(m byte, widthBytes, height int, data []byte) ([]byte, error) {
+	if err := oneOf("raster scale", m, 0, 1, 2, 3, 48, 49, 50, 51); err != nil {
+		return nil, err
+	}
+	if err := inRange("raster width", widthBytes, 1, 65535); err != nil {
+		return nil, err
+	}
+	if err := inRange("raster height", height, 1, 4095); err != nil {
+		return nil, err
+	}
+	if len(data) != widthBytes*height {
+		return nil, fmt.Errorf("cmd: raster data has %d bytes, want %d", len(data), widthBytes*height)
+	}
+	out := seq(GS, 'v', '0', m)
+	out = append(out, lowHigh(widthBytes)...)
+	out = append(out, lowHigh(height)...)
+	return append(out, data...), nil
+}
+
+// Function is GS ( fn pL pH d1...dk, the function-style commands such as
+// GS ( L (graphics) and GS ( k (2D codes). The length prefix is computed
+// from payload.
+func Function(fn byte, payload []byte) ([]byte, error) {
+	if err := inRange("function payload length", len(payload), 1, 65535); err != nil {
+		return nil, err
+	}
+	out := append(seq(GS, '('), fn)
+	out = append(out, lowHigh(len(payload))...)
+	return append(out, payload...), nil
+}
+
+// FunctionLarge is GS 8 fn p1 p2 p3 p4 d1...dk, the 4-byte length form of
+// Function used for large graphics data.
+func FunctionLarge(fn byte, payload []byte) ([]byte, error) {
+	if len(payload) == 0 || len(payload) > 0xffffffff {
+		return nil, fmt.Errorf("cmd: invalid function payload length %d", len(payload))
+	}
+	n := len(payload)
+	out := seq(GS, '8', fn, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+	return append(out, payload...), nil
+}
+
+// GraphicsStore is GS ( L / GS 8 L function 112: store a raster graphic of
+// width×height dots in the print buffer, scaled bx×by (1 or 2).
+func GraphicsStore(bx, by byte, width, height int, data []byte) ([]byte, error) {
+	if err := oneOf("graphics x scale", bx, 1, 2); err != nil {
+		return nil, err
+	}
+	if err := oneOf("graphics y scale", by, 1, 2); err != nil {
+		return nil, err
+	}
+	if err := inRange("graphics width", width, 1, 2400); err != nil {
+		return nil, err
+	}
+	if err := inRange("graphics height", height, 1, 2400); err != nil {
+		return nil, err
+	}
+	if want := (width + 7) / 8 * height; len(data) != want {
+		return nil, fmt.Errorf("cmd: graphics data has %d bytes, want %d", len(data), want)
+	}
+	payload := seq('0', 'p', '0', bx, by, '1')
+	payload = append(payload, lowHigh(width)...)
+	payload = append(payload, lowHigh(height)...)
+	payload = append(payload, data...)
+	if len(payload) <= 65535 {
+		return Function('L', payload)
+	}
+	return FunctionLarge('L', payload)
+}
+
+// GraphicsPrint is GS ( L function 50: print the stored graphics data.
+func GraphicsPrint() []byte {
+	return seq(GS, '(', 'L', 2, 0, '0', '2')
+}
+
+// QR error correction levels of QRCode.
+const (
+	QRLevelL = '0'
+	QRLevelM = '1'
+	QRLevelQ = '2'
+	QRLevelH = '3'
+)
+
+// QRCode is the GS ( k sequence that prints data as a model 2 QR code:
+// functions 165 (model), 167 (module size 1–16), 169 (error correction),
+// 180 (store) and 181 (print).
+func QRCode(data []byte, size byte, level byte) ([]byte, error) {
+	if err := inRange("QR module size", int(size), 1, 16); err != nil {
+		return nil, err
+	}
+	if err := oneOf("QR error correction level", level, QRLevelL, QRLevelM, QRLevelQ, QRLevelH); err != nil {
+		return nil, err
+	}
+	if err := inRange("QR data length", len(data), 1, 7089); err != nil {
+		return nil, err
+	}
+
+	var out []byte
+	for _, payload := range [][]byte{
+		seq('1', 'A', '2', 0),
+		seq('1', 'C', size),
+		seq('1', 'E', level),
+		append(seq('1', 'P', '0'), data...),
+		seq('1', 'Q', '0'),
+	} {
+		b, err := Function('k', payload)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b...)
+	}
+	return out, nil
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGsFixed(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"Reverse on", ok(Reverse(true)), []byte{GS, 'B', 1}, ""},
+		{"Reverse off", ok(Reverse(false)), []byte{GS, 'B', 0}, ""},
+		{"Smooth on", ok(Smooth(true)), []byte{GS, 'b', 1}, ""},
+		{"GraphicsPrint", ok(GraphicsPrint()), []byte{GS, '(', 'L', 2, 0, '0', '2'}, ""},
+	})
+}
+
+func TestCharSize(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"1x1", res(CharSize(1, 1)), []byte{GS, '!', 0x00}, ""},
+		{"2x1", res(CharSize(2, 1)), []byte{GS, '!', 0x10}, ""},
+		{"1x2", res(CharSize(1, 2)), []byte{GS, '!', 0x01}, ""},
+		{"8x8", res(CharSize(8, 8)), []byte{GS, '!', 0x77}, ""},
+		{"width 0", res(CharSize(0, 1)), nil, "character width 0 out of range"},
+		{"width 9", res(CharSize(9, 1)), nil, "character width 9 out of range"},
+		{"height 0", res(CharSize(1, 0)), nil, "character height 0 out of range"},
+		{"height 9", res(CharSize(1, 9)), nil, "character height 9 out of range"},
+	})
+}
+
+func TestCut(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"full", res(Cut(CutFull)), []byte{GS, 'V', 0}, ""},
+		{"partial", res(Cut(CutPartial)), []byte{GS, 'V', 1}, ""},
+		{"2", res(Cut(2)), nil, "invalid cut mode"},
+	})
+}
+
+func TestFeedAndCut(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"full", res(FeedAndCut(CutFull, 10)), []byte{GS, 'V', 65, 10}, ""},
+		{"partial", res(FeedAndCut(CutPartial, 0)), []byte{GS, 'V', 66, 0}, ""},
+		{"2", res(FeedAndCut(2, 0)), nil, "invalid cut mode"},
+	})
+}
+
+func TestLeftMargin(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"256", res(LeftMargin(256)), []byte{GS, 'L', 0, 1}, ""},
+		{"-1", res(LeftMargin(-1)), nil, "out of range"},
+		{"65536", res(LeftMargin(65536)), nil, "out of range"},
+	})
+}
+
+func TestPrintAreaWidth(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"576", res(PrintAreaWidth(576)), []byte{GS, 'W', 0x40, 0x02}, ""},
+		{"-1", res(PrintAreaWidth(-1)), nil, "out of range"},
+	})
+}
+
+func TestAbsoluteVerticalPosition(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"100", res(AbsoluteVerticalPosition(100)), []byte{GS, '$', 100, 0}, ""},
+		{"65536", res(AbsoluteVerticalPosition(65536)), nil, "out of range"},
+	})
+}
+
+func TestTransmitPrinterID(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"model", res(TransmitPrinterID(1)), []byte{GS, 'I', 1}, ""},
+		{"manufacturer", res(TransmitPrinterID(66)), []byte{GS, 'I', 66}, ""},
+		{"4", res(TransmitPrinterID(4)), nil, "invalid printer ID"},
+		{"70", res(TransmitPrinterID(70)), nil, "invalid printer ID"},
+	})
+}
+
+func TestBarcodeHeight(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"162", res(BarcodeHeight(162)), []byte{GS, 'h', 162}, ""},
+		{"0", res(BarcodeHeight(0)), nil, "out of range"},
+		{"256", res(BarcodeHeight(256)), nil, "out of range"},
+	})
+}
+
+func TestBarcodeWidth(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"2", res(BarcodeWidth(2)), []byte{GS, 'w', 2}, ""},
+		{"6", res(BarcodeWidth(6)), []byte{GS, 'w', 6}, ""},
+		{"1", res(BarcodeWidth(1)), nil, "out of range"},
+		{"7", res(BarcodeWidth(7)), nil, "out of range"},
+	})
+}
+
+func TestHRIPosition(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"below", res(HRIPosition(HRIBelow)), []byte{GS, 'H', 2}, ""},
+		{"4", res(HRIPosition(4)), nil, "invalid HRI position"},
+	})
+}
+
+func TestBarcode(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"EAN13", res(Barcode(BarcodeEAN13, []byte("4006381333931"))),
+			append([]byte{GS, 'k', 67, 13}, "4006381333931"...), ""},
+		{"CODE128", res(Barcode(BarcodeCODE128, []byte("{BA1"))),
+			[]byte{GS, 'k', 73, 4, '{', 'B', 'A', '1'}, ""},
+		{"function A system", res(Barcode(0, []byte("1"))), nil, "invalid barcode system"},
+		{"74", res(Barcode(74, []byte("1"))), nil, "invalid barcode system"},
+		{"empty", res(Barcode(BarcodeCODE39, nil)), nil, "out of range"},
+		{"256 bytes", res(Barcode(BarcodeCODE39, make([]byte, 256))), nil, "out of range"},
+	})
+}
+
+func TestRasterImage(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"2x2", res(RasterImage(0, 2, 2, []byte{1, 2, 3, 4})),
+			[]byte{GS, 'v', '0', 0, 2, 0, 2, 0, 1, 2, 3, 4}, ""},
+		{"quadruple", res(RasterImage(3, 1, 1, []byte{0xff})),
+			[]byte{GS, 'v', '0', 3, 1, 0, 1, 0, 0xff}, ""},
+		{"scale 4", res(RasterImage(4, 1, 1, []byte{0})), nil, "invalid raster scale"},
+		{"width 0", res(RasterImage(0, 0, 1, nil)), nil, "raster width 0 out of range"},
+		{"height 4096", res(RasterImage(0, 1, 4096, make([]byte, 4096))), nil, "raster height 4096 out of range"},
+		{"short data", res(RasterImage(0, 2, 2, []byte{1, 2, 3})), nil, "has 3 bytes, want 4"},
+	})
+}
+
+func TestFunction(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"GS ( k", res(Function('k', []byte{'1', 'Q', '0'})), []byte{GS, '(', 'k', 3, 0, '1', 'Q', '0'}, ""},
+		{"empty", res(Function('k', nil)), nil, "out of range"},
+		{"too long", res(Function('L', make([]byte, 65536))), nil, "out of range"},
+	})
+}
+
+func TestFunctionLarge(t *testing.T) {
+	runBuilderTests(t, []builderTest{
+		{"GS 8 L", res(FunctionLarge('L', []byte{'0', '2'})), []byte{GS, '8', 'L', 2, 0, 0, 0, '0', '2'}, ""},
+		{"empty", res(FunctionLarge('L', nil)), nil, "invalid function payload length"},
+	})
+}
+
+func TestGraphicsStore(t *testing.T) {
+	head := func(bx, by byte, w, h int) []byte {
+		return []byte{'0', 'p', '0', bx, by, '1', byte(w), byte(w >> 8), byte(h), byte(h >> 8)}
+	}
+	small := append(head(1, 1, 8, 2), 0xaa, 0x55)
+	// 2400 dots wide is 300 bytes a row; 300 rows take GS 8 L
+	large := append(head(2, 2, 2400, 300), make([]byte, 300*300)...)
+	runBuilderTests(t, []builderTest{
+		{"GS ( L", res(GraphicsStore(1, 1, 8, 2, []byte{0xaa, 0x55})),
+			append([]byte{GS, '(', 'L', byte(len(small)), 0}, small...), ""},
+		{"GS 8 L", res(GraphicsStore(2, 2, 2400, 300, make([]byte, 300*300))),
+			append([]byte{GS, '8', 'L', byte(len(large)), byte(len(large) >> 8), byte(len(large) >> 16), 0}, large...), ""},
+		{"scale 3", res(GraphicsStore(3, 1, 8, 1, []byte{0})), nil, "invalid graphics x scale"},
+		{"y scale 0", res(GraphicsStore(1, 0, 8, 1, []byte{0})), nil, "invalid graphics y scale"},
+		{"width 2401", res(GraphicsStore(1, 1, 2401, 1, make([]byte, 301))), nil, "graphics width 2401 out of range"},
+		{"height 0", res(GraphicsStore(1, 1, 8, 0, nil)), nil, "graphics height 0 out of range"},
+		{"short data", res(GraphicsStore(1, 1, 9, 1, []byte{0})), nil, "has 1 bytes, want 2"},
+	})
+}
+
+func TestQRCode(t *testing.T) {
+	want := bytes.Join([][]byte{
+		{GS, '(', 'k', 4, 0, '1', 'A', '2', 0},
+		{GS, '(', 'k', 3, 0, '1', 'C', 6},
+		{GS, '(', 'k', 3, 0, '1', 'E', QRLevelM},
+		{GS, '(', 'k', 5, 0, '1', 'P', '0', 'h', 'i'},
+		{GS, '(', 'k', 3, 0, '1', 'Q', '0'},
+	}, nil)
+	runBuilderTests(t, []builderTest{
+		{"hi", res(QRCode([]byte("hi"), 6, QRLevelM)), want, ""},
+		{"size 0", res(QRCode([]byte("hi"), 0, QRLevelM)), nil, "QR module size 0 out of range"},
+		{"size 17", res(QRCode([]byte("hi"), 17, QRLevelM)), nil, "QR module size 17 out of range"},
+		{"level", res(QRCode([]byte("hi"), 6, 'L')), nil, "invalid QR error correction level"},
+		{"empty", res(QRCode(nil, 6, QRLevelL)), nil, "QR data length 0 out of range"},
+		{"too long", res(QRCode(make([]byte, 7090), 6, QRLevelL)), nil, "QR data length 7090 out of range"},
+	})
+}
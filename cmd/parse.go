@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors wrapped by ParseError.
+var (
+	ErrTruncated = errors.New("truncated command")
+	ErrUnknown   = errors.New("unknown command")
+)
+
+// Token is one command, or a run of printable text, of an ESC/POS stream.
+type Token struct {
+	// Name is the command as written in the ESC/POS reference, e.g. "ESC t"
+	// or "GS ( k"; "text" for printable bytes.
+	Name  string
+	Bytes []byte
+}
+
+// ParseError reports where Parse gave up.
+type ParseError struct {
+	Offset int
+	Name   string // command name so far, e.g. "GS ( L" or "0x07"
+	Err    error  // ErrTruncated or ErrUnknown
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("cmd: %v %s at offset %d", e.Err, e.Name, e.Offset)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// params returns the number of parameter and data bytes that follow a
+// command, given the bytes after it; -1 means the command is cut short.
+type params func(p []byte) int
+
+func fixed(n int) params {
+	return func(p []byte) int {
+		if len(p) < n {
+			return -1
+		}
+		return n
+	}
+}
+
+// counted is for commands whose data length is given by nh bytes of
+// little-endian count at offset off, times mul, plus a fixed head.
+func counted(off, nh, mul, head int) params {
+	return func(p []byte) int {
+		if len(p) < off+nh {
+			return -1
+		}
+		k := 0
+		for i := nh - 1; i >= 0; i-- {
+			k = k<<8 | int(p[off+i])
+		}
+		n := head + k*mul
+		if len(p) < n {
+			return -1
+		}
+		return n
+	}
+}
+
+// untilNUL is for commands terminated by NUL, with at most max bytes.
+func untilNUL(max int) params {
+	return func(p []byte) int {
+		for i := 0; i < len(p) && i <= max; i++ {
+			if p[i] == 0 {
+				return i + 1
+			}
+		}
+		return -1
+	}
+}
+
+var escCommands = map[byte]params{
+	' ': fixed(1), '!': fixed(1), '$': fixed(2), '%': fixed(1), '&': userChars,
+	'*': bitImage, '-': fixed(1), '2': fixed(0), '3': fixed(1), '=': fixed(1),
+	'?': fixed(1), '@': fixed(0), 'D': untilNUL(32), 'E': fixed(1), 'G': fixed(1),
+	'J': fixed(1), 'K': fixed(1), 'L': fixed(0), 'M': fixed(1), 'R': fixed(1),
+	'S': fixed(0), 'T': fixed(1), 'U': fixed(1), 'V': fixed(1), 'W': fixed(8),
+	'\\': fixed(2), 'a': fixed(1), 'c': fixed(2), 'd': fixed(1), 'e': fixed(1),
+	'i': fixed(0), 'm': fixed(0), 'p': fixed(3), 'r': fixed(1), 't': fixed(1),
+	'u': fixed(1), 'v': fixed(0), '{': fixed(1), FF: fixed(0),
+}
+
+var gsCommands = map[byte]params{
+	'!': fixed(1), '$': fixed(2), '(': counted(1, 2, 1, 3), '*': gsDefineImage,
+	'/': fixed(1), '8': counted(1, 4, 1, 5), ':': fixed(0), 'B': fixed(1),
+	'H': fixed(1), 'I': fixed(1), 'L': fixed(2), 'P': fixed(2), 'T': fixed(1),
+	'V': gsCut, 'W': fixed(2), '\\': fixed(2), '^': fixed(3), 'a': fixed(1),
+	'b': fixed(1), 'c': fixed(0), 'f': fixed(1), 'h': fixed(1), 'k': gsBarcode,
+	'r': fixed(1), 'v': gsRaster, 'w': fixed(1),
+}
+
+var fsCommands = map[byte]params{
+	'!': fixed(1), '&': fixed(0), '-': fixed(1), '.': fixed(0), '2': fixed(74),
+	'C': fixed(1), 'S': fixed(2), 'W': fixed(1), '(': counted(1, 2, 1, 3),
+	'p': fixed(2),
+}
+
+var dleCommands = map[byte]params{
+	EOT: dleStatus, ENQ: fixed(1), DC4: dleFunction,
+}
+
+var prefixes = map[byte]struct {
+	name     string
+	commands map[byte]params
+}{
+	ESC: {"ESC", escCommands},
+	GS:  {"GS", gsCommands},
+	FS:  {"FS", fsCommands},
+	DLE: {"DLE", dleCommands},
+}
+
+// userChars is ESC & y c1 c2 [x d1...d(y×x)]×(c2-c1+1).
+func userChars(p []byte) int {
+	if len(p) < 3 {
+		return -1
+	}
+	y, c1, c2 := int(p[0]), int(p[1]), int(p[2])
+	n := 3
+	for c := c1; c <= c2; c++ {
+		if len(p) < n+1 {
+			return -1
+		}
+		n += 1 + y*int(p[n])
+	}
+	if len(p) < n {
+		return -1
+	}
+	return n
+}
+
+// bitImage is ESC * m nL nH d1...dk with 3 bytes per column for m 32, 33.
+func bitImage(p []byte) int {
+	if len(p) < 1 {
+		return -1
+	}
+	mul := 1
+	if p[0] >= 32 {
+		mul = 3
+	}
+	return counted(1, 2, mul, 3)(p)
+}
+
+// gsDefineImage is GS * x y d1...d(x×y×8).
+func gsDefineImage(p []byte) int {
+	if len(p) < 2 {
+		return -1
+	}
+	n := 2 + int(p[0])*int(p[1])*8
+	if len(p) < n {
+		return -1
+	}
+	return n
+}
+
+// dleStatus is DLE EOT n, or DLE EOT n a for the ink and paper statuses
+// n = 7 and 8.
+func dleStatus(p []byte) int {
+	if len(p) < 1 {
+		return -1
+	}
+	if p[0] == 7 || p[0] == 8 {
+		return fixed(2)(p)
+	}
+	return 1
+}
+
+// dleFunction is DLE DC4 fn with its parameters: fn m t for the pulse and
+// fn a b for power off, fn m for specified status (7) and fn d1...d7 for
+// clearing the buffers (8).
+func dleFunction(p []byte) int {
+	if len(p) < 1 {
+		return -1
+	}
+	switch p[0] {
+	case 7:
+		return fixed(2)(p)
+	case 8:
+		return fixed(8)(p)
+	}
+	return fixed(3)(p)
+}
+
+// gsCut is GS V m, or GS V m n for function B and later.
+func gsCut(p []byte) int {
+	if len(p) < 1 {
+		return -1
+	}
+	switch p[0] {
+	case 0, 1, '0', '1':
+		return 1
+	}
+	return fixed(2)(p)
+}
+
+// gsBarcode is GS k m d1...NUL (function A) or GS k m n d1...dn.
+func gsBarcode(p []byte) int {
+	if len(p) < 1 {
+		return -1
+	}
+	if p[0] <= 6 {
+		n := untilNUL(255)(p[1:])
+		if n < 0 {
+			return -1
+		}
+		return 1 + n
+	}
+	return counted(1, 1, 1, 2)(p)
+}
+
+// gsRaster is GS v 0 m xL xH yL yH d1...d(x×y).
+func gsRaster(p []byte) int {
+	if len(p) < 6 {
+		return -1
+	}
+	n := 6 + (int(p[2])|int(p[3])<<8)*(int(p[4])|int(p[5])<<8)
+	if len(p) < n {
+		return -1
+	}
+	return n
+}
+
+// plainControls are the single-byte commands Parse accepts.
+var plainControls = map[byte]string{
+	HT: "HT", LF: "LF", FF: "FF", CR: "CR", CAN: "CAN",
+}
+
+// Parse splits an ESC/POS stream into commands and text runs. It fails on
+// the first command it does not know or that is cut short.
+func Parse(b []byte) ([]Token, error) {
+	var out []Token
+	for i := 0; i < len(b); {
+		c := b[i]
+		if c >= 0x20 {
+			j := i + 1
+			for j < len(b) && b[j] >= 0x20 {
+				j++
+			}
+			out = append(out, Token{Name: "text", Bytes: b[i:j]})
+			i = j
+			continue
+		}
+		if name, ok := plainControls[c]; ok {
+			out = append(out, Token{Name: name, Bytes: b[i : i+1]})
+			i++
+			continue
+		}
+
+		prefix, ok := prefixes[c]
+		if !ok {
+			return nil, &ParseError{Offset: i, Name: fmt.Sprintf("0x%02x", c), Err: ErrUnknown}
+		}
+		if i+1 >= len(b) {
+			return nil, &ParseError{Offset: i, Name: prefix.name, Err: ErrTruncated}
+		}
+		name := commandName(prefix.name, b[i+1])
+		if (b[i+1] == '(' || prefix.name == "GS" && b[i+1] == '8') && i+2 < len(b) {
+			// function commands are named by their function letter
+			name += " " + string(b[i+2])
+		}
+		param, ok := prefix.commands[b[i+1]]
+		if !ok {
+			return nil, &ParseError{Offset: i, Name: name, Err: ErrUnknown}
+		}
+		n := param(b[i+2:])
+		if n < 0 {
+			return nil, &ParseError{Offset: i, Name: name, Err: ErrTruncated}
+		}
+		out = append(out, Token{Name: name, Bytes: b[i : i+2+n]})
+		i += 2 + n
+	}
+	return out, nil
+}
+
+func commandName(prefix string, c byte) string {
+	switch {
+	case c == FF:
+		return prefix + " FF"
+	case c == EOT:
+		return prefix + " EOT"
+	case c == ENQ:
+		return prefix + " ENQ"
+	case c == DC4:
+		return prefix + " DC4"
+	case c < 0x20 || c >= 0x7f:
+		return fmt.Sprintf("%s 0x%02x", prefix, c)
+	}
+	return prefix + " " + string(c)
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cat := func(parts ...[]byte) []byte { return bytes.Join(parts, nil) }
+	must := func(b []byte, err error) []byte {
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	tests := []struct {
+		name string
+		in   []byte
+		// name:length of each token
+		want []string
+		err  error
+	}{
+		{"text and controls", []byte("ab\ncd\r\x09"), []string{"text:2", "LF:1", "text:2", "CR:1", "HT:1"}, nil},
+		{"fixed", cat(Init(), Emphasize(true), CodeTable(17)), []string{"ESC @:2", "ESC E:3", "ESC t:3"}, nil},
+
+		// ESC * m nL nH: 1 byte per column for m 0, 1, 3 for m 32, 33
+		{"ESC * 8-dot", cat([]byte{ESC, '*', 0, 3, 0, 1, 2, 3}, []byte("x")), []string{"ESC *:8", "text:1"}, nil},
+		{"ESC * 24-dot", []byte{ESC, '*', 33, 2, 0, 1, 2, 3, 4, 5, 6}, []string{"ESC *:11"}, nil},
+		{"ESC * 256 columns", append([]byte{ESC, '*', 1, 0, 1}, make([]byte, 256)...), []string{"ESC *:261"}, nil},
+		{"ESC * truncated", []byte{ESC, '*', 33, 2, 0, 1, 2, 3, 4, 5}, nil, ErrTruncated},
+
+		// ESC & y c1 c2, then x and y×x bytes per character
+		{"ESC &", cat(must(DefineUserChars(2, 'A', 'B', []UserChar{
+			{Width: 2, Data: []byte{1, 2, 3, 4}}, {Width: 1, Data: []byte{5, 6}},
+		})), []byte("AB")), []string{"ESC &:13", "text:2"}, nil},
+		{"ESC & empty glyph", []byte{ESC, '&', 3, 'A', 'A', 0}, []string{"ESC &:6"}, nil},
+		{"ESC & truncated", []byte{ESC, '&', 3, 'A', 'B', 1, 1, 2, 3}, nil, ErrTruncated},
+
+		// GS ( fn pL pH and GS 8 fn p1..p4 count the bytes after them
+		{"GS ( k", must(QRCode([]byte("hi"), 4, QRLevelL)),
+			[]string{"GS ( k:9", "GS ( k:8", "GS ( k:8", "GS ( k:10", "GS ( k:8"}, nil},
+		{"GS ( L", cat(must(GraphicsStore(1, 1, 8, 1, []byte{0xff})), GraphicsPrint()),
+			[]string{"GS ( L:16", "GS ( L:7"}, nil},
+		{"GS ( truncated", []byte{GS, '(', 'k', 3, 0, '1', 'Q'}, nil, ErrTruncated},
+		{"GS 8 L", must(FunctionLarge('L', make([]byte, 70000))), []string{"GS 8 L:70007"}, nil},
+		{"GS 8 L truncated", []byte{GS, '8', 'L', 3, 0, 0, 0, 1, 2}, nil, ErrTruncated},
+
+		// GS v 0 m xL xH yL yH
+		{"GS v 0", cat(must(RasterImage(0, 3, 2, make([]byte, 6))), LineFeed()), []string{"GS v:14", "LF:1"}, nil},
+		{"GS v 0 wide", must(RasterImage(0, 256, 1, make([]byte, 256))), []string{"GS v:264"}, nil},
+		{"GS v 0 truncated", []byte{GS, 'v', '0', 0, 1, 0, 2, 0, 0}, nil, ErrTruncated},
+
+		// GS k m ... NUL (function A), GS k m n ... (function B)
+		{"GS k function A", cat([]byte{GS, 'k', 4}, []byte("*AB*"), []byte{0}, []byte("x")), []string{"GS k:8", "text:1"}, nil},
+		{"GS k function B", must(Barcode(BarcodeEAN8, []byte("96385074"))), []string{"GS k:12"}, nil},
+		{"GS k function A truncated", cat([]byte{GS, 'k', 4}, []byte("*AB*")), nil, ErrTruncated},
+		{"GS k function B truncated", []byte{GS, 'k', 73, 4, '{', 'B'}, nil, ErrTruncated},
+
+		// DLE EOT 7 and 8 and DLE DC4 7 and 8 take more than the others
+		{"DLE EOT", must(TransmitStatus(StatusPaper)), []string{"DLE EOT:3"}, nil},
+		{"DLE EOT 7", []byte{DLE, EOT, 7, 1, 'x'}, []string{"DLE EOT:4", "text:1"}, nil},
+		{"DLE EOT 8", []byte{DLE, EOT, 8, 3, 'x'}, []string{"DLE EOT:4", "text:1"}, nil},
+		{"DLE EOT 7 truncated", []byte{DLE, EOT, 7}, nil, ErrTruncated},
+		{"DLE DC4 1", must(RealtimePulse(0, 1)), []string{"DLE DC4:5"}, nil},
+		{"DLE DC4 7", []byte{DLE, DC4, 7, 1, 'x'}, []string{"DLE DC4:4", "text:1"}, nil},
+		{"DLE DC4 8", []byte{DLE, DC4, 8, 1, 3, 20, 1, 6, 2, 8, 'x'}, []string{"DLE DC4:10", "text:1"}, nil},
+		{"DLE DC4 8 truncated", []byte{DLE, DC4, 8, 1, 3, 20, 1, 6, 2}, nil, ErrTruncated},
+
+		{"unknown", []byte{ESC, 'Z'}, nil, ErrUnknown},
+		{"unknown control", []byte{0x07}, nil, ErrUnknown},
+		{"bare ESC", []byte{ESC}, nil, ErrTruncated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := Parse(tt.in)
+			if tt.err != nil {
+				var pe *ParseError
+				if !errors.Is(err, tt.err) || !errors.As(err, &pe) {
+					t.Fatalf("error %v, want a ParseError with %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			var all []byte
+			for _, tok := range tokens {
+				got = append(got, fmt.Sprintf("%s:%d", tok.Name, len(tok.Bytes)))
+				all = append(all, tok.Bytes...)
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("tokens %v, want %v", got, tt.want)
+			}
+			if !bytes.Equal(all, tt.in) {
+				t.Error("tokens do not add up to the input")
+			}
+		})
+	}
+}
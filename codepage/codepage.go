@@ -0,0 +1,109 @@
+// Package codepage maps Unicode text to the single-byte code pages of
+// receipt printers.
+package codepage
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Table is a single-byte code page: the Unicode character of each of the
+// 256 byte values.
+type Table struct {
+	name  string
+	runes [256]rune
+	index map[rune]byte
+}
+
+// NewTable builds a table from the characters of the 256 byte values;
+// utf8.RuneError marks a byte with no character. Where two bytes map to the
+// same character the lower one is used for encoding.
+func NewTable(name string, runes [256]rune) *Table {
+	t := &Table{name: name, runes: runes, index: make(map[rune]byte, 256)}
+	for b := 255; b >= 0; b-- {
+		if r := runes[b]; r != utf8.RuneError {
+			t.index[r] = byte(b)
+		}
+	}
+	return t
+}
+
+// Name returns the code page name, e.g. "CP866".
+func (t *Table) Name() string {
+	return t.name
+}
+
+// Decode returns the character of byte b, or utf8.RuneError.
+func (t *Table) Decode(b byte) rune {
+	return t.runes[b]
+}
+
+// Encode returns the byte of r in the code page.
+func (t *Table) Encode(r rune) (byte, bool) {
+	b, ok := t.index[r]
+	return b, ok
+}
+
+// Covers returns how many characters of s the code page can encode.
+func (t *Table) Covers(s string) int {
+	n := 0
+	for _, r := range s {
+		if _, ok := t.index[r]; ok {
+			n++
+		}
+	}
+	return n
+}
+
+// EncodeString encodes s, replacing characters missing from the code page
+// with replacement.
+func (t *Table) EncodeString(s string, replacement byte) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		b, ok := t.index[r]
+		if !ok {
+			b = replacement
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+var (
+	mu     sync.RWMutex
+	tables = map[string]*Table{}
+)
+
+// key normalizes code page names, so that "cp866", "CP-866" and "CP866"
+// are the same.
+func key(name string) string {
+	return strings.NewReplacer("-", "", "_", "", " ", "").Replace(strings.ToUpper(name))
+}
+
+func register(t *Table) {
+	mu.Lock()
+	defer mu.Unlock()
+	tables[key(t.name)] = t
+}
+
+// Lookup returns the table of the named code page.
+func Lookup(name string) (*Table, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	t, ok := tables[key(name)]
+	return t, ok
+}
+
+// Names lists the known code pages.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(tables))
+	for _, t := range tables {
+		names = append(names, t.name)
+	}
+	sort.Strings(names)
+	return names
+}
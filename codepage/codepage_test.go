@@ -0,0 +1,95 @@
+package codepage
+
+import (
+	"bytes"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEncodeString(t *testing.T) {
+	tests := []struct {
+		page, s string
+		want    []byte
+	}{
+		{"CP437", "abc", []byte("abc")},
+		{"CP866", "Привет", []byte{0x8f, 0xe0, 0xa8, 0xa2, 0xa5, 0xe2}},
+		{"cp-866", "ё", []byte{0xf1}},
+		{"CP1252", "€ é", []byte{0x80, ' ', 0xe9}},
+		{"CP1251", "Їж", []byte{0xaf, 0xe6}},
+		{"KZ-1048", "Қазақ", []byte{0x8d, 0xe0, 0xe7, 0xe0, 0x9d}},
+		{"KZ-1048", "ӘәҢңҒғҮүҰұӨөҺһ", []byte{0xa3, 0xbc, 0xbd, 0xbe, 0xaa, 0xba, 0xaf, 0xbf, 0xa1, 0xa2, 0xa5, 0xb4, 0x8e, 0x9e}},
+		{"PC1125", "Їжак", []byte{0xf8, 0xa6, 0xa0, 0xaa}},
+		{"PC1125", "ҐґЄєІіЇї", []byte{0xf2, 0xf3, 0xf4, 0xf5, 0xf6, 0xf7, 0xf8, 0xf9}},
+		{"CP737", "Αθήνα", []byte{0x80, 0x9f, 0xe3, 0xa4, 0x98}},
+		{"CP737", "Σς ±Ώ", []byte{0x91, 0xaa, ' ', 0xf1, 0xf0}},
+		{"CP737", "é", []byte{'?'}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.page+" "+tt.s, func(t *testing.T) {
+			table, ok := Lookup(tt.page)
+			if !ok {
+				t.Fatalf("no code page %s", tt.page)
+			}
+			got := table.EncodeString(tt.s, '?')
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("% x, want % x", got, tt.want)
+			}
+			for i, r := range []rune(tt.s) {
+				if got[i] != '?' && table.Decode(got[i]) != r {
+					t.Errorf("byte %#x decodes to %q, want %q", got[i], table.Decode(got[i]), r)
+				}
+			}
+		})
+	}
+}
+
+func TestPatchedTablesKeepTheirBase(t *testing.T) {
+	tests := []struct {
+		page, base string
+		patched    string
+	}{
+		{"KZ-1048", "CP1251", "ҚҺқһҰұӘӨҒҮөғәҢңү"},
+		{"PC1125", "CP866", "ҐґЄєІіЇї"},
+		{"CP737", "CP437", "ΑΒΓΔΕΖΗΘΙΚΛΜΝΞΟΠΡΣΤΥΦΧΨΩαβγδεζηθικλμνξοπρσςτυφχψωάέήϊίόύϋώΆΈΉΊΌΎΏ±≥≤ΪΫ÷≈°∙·√ⁿ²■\u00a0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.page, func(t *testing.T) {
+			table, _ := Lookup(tt.page)
+			base, _ := Lookup(tt.base)
+			patched := map[byte]bool{}
+			for _, r := range tt.patched {
+				b, ok := table.Encode(r)
+				if !ok {
+					t.Fatalf("%q missing", r)
+				}
+				patched[b] = true
+			}
+			for b := range 256 {
+				if !patched[byte(b)] && table.Decode(byte(b)) != base.Decode(byte(b)) {
+					t.Errorf("byte %#x is %q, want %q of %s", b, table.Decode(byte(b)), base.Decode(byte(b)), tt.base)
+				}
+			}
+		})
+	}
+}
+
+func TestLookupUnknown(t *testing.T) {
+	if _, ok := Lookup("CP9999"); ok {
+		t.Error("CP9999 found")
+	}
+	if r := mustLookup(t, "CP437").Decode(0x41); r != 'A' {
+		t.Errorf("CP437 0x41 is %q", r)
+	}
+	if mustLookup(t, "CP1252").Decode(0x81) != utf8.RuneError {
+		t.Error("CP1252 0x81 has a character")
+	}
+}
+
+func mustLookup(t *testing.T, name string) *Table {
+	t.Helper()
+	table, ok := Lookup(name)
+	if !ok {
+		t.Fatalf("no code page %s", name)
+	}
+	return table
+}
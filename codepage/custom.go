@@ -0,0 +1,75 @@
+package codepage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Register adds t to the known code pages, replacing any table of the
+// same name.
+func Register(t *Table) {
+	register(t)
+}
+
+// FromMap builds a table from the characters of the bytes in m. Bytes
+// below 0x80 that m leaves out are ASCII, the others have no character.
+func FromMap(name string, m map[byte]rune) *Table {
+	var runes [256]rune
+	for b := range 256 {
+		if b < utf8.RuneSelf {
+			runes[b] = rune(b)
+		} else {
+			runes[b] = utf8.RuneError
+		}
+	}
+	for b, r := range m {
+		runes[b] = r
+	}
+	return NewTable(name, runes)
+}
+
+// Parse reads a mapping of one byte per line, "0xNN U+XXXX", and builds a
+// table from it as FromMap does. The character may also be written 0xXXXX,
+// as in the unicode.org mapping files; "#" starts a comment and lines
+// without a character are skipped.
+func Parse(name string, r io.Reader) (*Table, error) {
+	m := map[byte]rune{}
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text, _, _ := strings.Cut(sc.Text(), "#")
+		fields := strings.Fields(text)
+		if len(fields) < 2 {
+			continue
+		}
+		b, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(fields[0]), "0x"), 16, 8)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: invalid byte %q", name, line, fields[0])
+		}
+		u := strings.ToLower(fields[1])
+		u = strings.TrimPrefix(strings.TrimPrefix(u, "u+"), "0x")
+		c, err := strconv.ParseUint(u, 16, 32)
+		if err != nil || !utf8.ValidRune(rune(c)) {
+			return nil, fmt.Errorf("%s line %d: invalid character %q", name, line, fields[1])
+		}
+		m[byte(b)] = rune(c)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return FromMap(name, m), nil
+}
+
+// LoadFile reads a mapping file as Parse does.
+func LoadFile(name, path string) (*Table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(name, f)
+}
@@ -0,0 +1,82 @@
+package codepage
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestFromMap(t *testing.T) {
+	table := FromMap("Georgian", map[byte]rune{0x80: 'ა', 0x81: 'ბ', 0x41: 'Ⴀ'})
+	if got := table.EncodeString("აბa", '?'); !bytes.Equal(got, []byte{0x80, 0x81, 'a'}) {
+		t.Errorf("% x", got)
+	}
+	if table.Decode(0x41) != 'Ⴀ' {
+		t.Errorf("0x41 is %q, want the mapped Ⴀ", table.Decode(0x41))
+	}
+	if table.Decode(0x82) != utf8.RuneError {
+		t.Errorf("unmapped 0x82 is %q", table.Decode(0x82))
+	}
+	if _, ok := table.Encode('A'); ok {
+		t.Error("A encodes though 0x41 was remapped")
+	}
+}
+
+const georgianMapping = `# Georgian test mapping
+0x80	0x10D0	# ა
+0x81 U+10D1
+0X82 u+10d2
+
+0x83	# no character
+`
+
+func TestParse(t *testing.T) {
+	table, err := Parse("Georgian", strings.NewReader(georgianMapping))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := table.EncodeString("ა ბ გ", '?'); !bytes.Equal(got, []byte{0x80, ' ', 0x81, ' ', 0x82}) {
+		t.Errorf("% x", got)
+	}
+	if table.Decode(0x83) != utf8.RuneError {
+		t.Errorf("0x83 is %q", table.Decode(0x83))
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		in, err string
+	}{
+		{"0x100 U+0041", "line 1: invalid byte"},
+		{"# x\nzz U+0041", "line 2: invalid byte"},
+		{"0x80 U+XYZ", "line 1: invalid character"},
+		{"0x80 U+D800", "line 1: invalid character"},
+		{"0x80 U+110000", "line 1: invalid character"},
+	}
+	for _, tt := range tests {
+		_, err := Parse("bad", strings.NewReader(tt.in))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%q: error %v, want %q", tt.in, err, tt.err)
+		}
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "georgian.txt")
+	if err := os.WriteFile(path, []byte(georgianMapping), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	table, err := LoadFile("Georgian", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Name() != "Georgian" || table.Decode(0x81) != 'ბ' {
+		t.Errorf("%s 0x81 is %q", table.Name(), table.Decode(0x81))
+	}
+	if _, err := LoadFile("none", filepath.Join(t.TempDir(), "none.txt")); err == nil {
+		t.Error("no error for a missing file")
+	}
+}
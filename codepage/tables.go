@@ -0,0 +1,88 @@
+package codepage
+
+import "golang.org/x/text/encoding/charmap"
+
+// builtin are the code pages taken from golang.org/x/text.
+var builtin = []struct {
+	name string
+	cm   *charmap.Charmap
+}{
+	{"CP437", charmap.CodePage437},
+	{"CP850", charmap.CodePage850},
+	{"CP852", charmap.CodePage852},
+	{"CP855", charmap.CodePage855},
+	{"CP858", charmap.CodePage858},
+	{"CP860", charmap.CodePage860},
+	{"CP862", charmap.CodePage862},
+	{"CP863", charmap.CodePage863},
+	{"CP865", charmap.CodePage865},
+	{"CP866", charmap.CodePage866},
+	{"CP1250", charmap.Windows1250},
+	{"CP1251", charmap.Windows1251},
+	{"CP1252", charmap.Windows1252},
+	{"CP1253", charmap.Windows1253},
+	{"CP1254", charmap.Windows1254},
+	{"CP1255", charmap.Windows1255},
+	{"CP1256", charmap.Windows1256},
+	{"CP1257", charmap.Windows1257},
+	{"CP1258", charmap.Windows1258},
+	{"ISO8859-2", charmap.ISO8859_2},
+	{"ISO8859-7", charmap.ISO8859_7},
+	{"ISO8859-15", charmap.ISO8859_15},
+	{"KOI8-R", charmap.KOI8R},
+	{"KOI8-U", charmap.KOI8U},
+}
+
+// kz1048 is CP1251 with the Kazakh letters in place of the Serbian,
+// Macedonian and Ukrainian ones.
+var kz1048 = map[byte]rune{
+	0x8d: 'Қ', 0x8e: 'Һ', 0x9d: 'қ', 0x9e: 'һ',
+	0xa1: 'Ұ', 0xa2: 'ұ', 0xa3: 'Ә', 0xa5: 'Ө', 0xaa: 'Ғ', 0xaf: 'Ү',
+	0xb4: 'ө', 0xba: 'ғ', 0xbc: 'ә', 0xbd: 'Ң', 0xbe: 'ң', 0xbf: 'ү',
+}
+
+// pc1125 (RUSCII) is CP866 with the Ukrainian letters in 0xF2–0xF9.
+var pc1125 = map[byte]rune{
+	0xf2: 'Ґ', 0xf3: 'ґ', 0xf4: 'Є', 0xf5: 'є',
+	0xf6: 'І', 0xf7: 'і', 0xf8: 'Ї', 0xf9: 'ї',
+}
+
+// cp737 is CP437 with Greek in 0x80–0xAF and 0xE0–0xFF.
+var cp737 = func() map[byte]rune {
+	patch := map[byte]rune{}
+	for _, run := range []struct {
+		at    int
+		runes string
+	}{
+		{0x80, "ΑΒΓΔΕΖΗΘΙΚΛΜΝΞΟΠΡΣΤΥΦΧΨΩαβγδεζηθικλμνξοπρσςτυφχψ"},
+		{0xe0, "ωάέήϊίόύϋώΆΈΉΊΌΎΏ±≥≤ΪΫ÷≈°∙·√ⁿ²■\u00a0"},
+	} {
+		for i, r := range []rune(run.runes) {
+			patch[byte(run.at+i)] = r
+		}
+	}
+	return patch
+}()
+
+func init() {
+	for _, b := range builtin {
+		register(NewTable(b.name, charmapRunes(b.cm, nil)))
+	}
+	register(NewTable("KZ-1048", charmapRunes(charmap.Windows1251, kz1048)))
+	register(NewTable("PC1125", charmapRunes(charmap.CodePage866, pc1125)))
+	register(NewTable("CP737", charmapRunes(charmap.CodePage437, cp737)))
+}
+
+// charmapRunes returns the characters of cm with the bytes in patch
+// replaced.
+func charmapRunes(cm *charmap.Charmap, patch map[byte]rune) [256]rune {
+	var runes [256]rune
+	for b := range 256 {
+		// undefined bytes decode to utf8.RuneError
+		runes[b] = cm.DecodeByte(byte(b))
+	}
+	for b, r := range patch {
+		runes[b] = r
+	}
+	return runes
+}
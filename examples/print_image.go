@@ -15,5 +15,7 @@ func PrintImage() {
 		log.Println("Ошибка изображения:", err)
 	}
 	p.Linefeed()
-	p.Cut()
+	if err := p.Cut(); err != nil {
+		log.Println("Ошибка печати:", err)
+	}
 }
@@ -12,6 +12,8 @@ func PrintToSerial() {
 		log.Fatal("Serial error: ", err)
 	}
 	p.Init()
-	p.Write([]byte("Проверка связи\n"))
-	p.Cash()
+	p.Text("Проверка связи\n")
+	if err := p.Cash(); err != nil {
+		log.Println("Ошибка печати:", err)
+	}
 }
@@ -15,6 +15,8 @@ func PrintToUSB() {
 	p.Init()
 	p.SetAlign("center")
 	p.SetFontSize(2, 2)
-	p.Write([]byte("Добро пожаловать!\n"))
-	p.Cut()
+	p.Text("Добро пожаловать!\n")
+	if err := p.Cut(); err != nil {
+		log.Println("Ошибка печати:", err)
+	}
 }
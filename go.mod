@@ -6,6 +6,8 @@ require (
 	github.com/google/gousb v1.1.3
 	github.com/nfnt/resize v0.0.0-20180221191011-83c6a9932646
 	go.bug.st/serial v1.6.4
+	golang.org/x/text v0.29.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
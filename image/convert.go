@@ -1,11 +1,9 @@
 package image
 
 import (
-	"fmt"
 	"image"
 	"image/color"
-
-	logInternal "github.com/AlexStarov/escpos-GoLang-lib/log" // Убедитесь, что пакет image импортирован корректно
+	"log/slog"
 )
 
 const gs8lMaxY = 831
@@ -16,11 +14,16 @@ type Converter struct {
 
 	// The threashold between white and black dots
 	Threshold float64
+
+	// Optional logger, nothing is logged if nil
+	Logger *slog.Logger
 }
 
-func (c *Converter) Print(img image.Image, target Target) {
+func (c *Converter) Print(img image.Image, target Target) error {
 	sz := img.Bounds().Size()
-	logInternal.LogMessage(logInternal.INFO, fmt.Sprintf("sz: %+v, X: %d, Y: %d", sz, sz.X, sz.Y))
+	if c.Logger != nil {
+		c.Logger.Debug("converting image", "width", sz.X, "height", sz.Y)
+	}
 
 	data, rw, bw := c.ToRaster(img)
 
@@ -29,7 +32,7 @@ func (c *Converter) Print(img image.Image, target Target) {
 		mode = "graphics"
 	}
 
-	target.Raster(rw, sz.Y, bw, data, mode)
+	return target.Raster(rw, sz.Y, bw, data, mode)
 }
 func (c *Converter) ToRaster(img image.Image) (data []byte, imageWidth, bytesWidth int) {
 	sz := img.Bounds().Size()
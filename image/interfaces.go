@@ -2,5 +2,5 @@ package image
 
 // Target — интерфейс, который должен реализовывать приёмник растровых данных
 type Target interface {
-	Raster(width, height, bytesWidth int, rasterData []byte, printingType string)
+	Raster(width, height, bytesWidth int, rasterData []byte, printingType string) error
 }
@@ -0,0 +1,280 @@
+package printer
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotateConfig configures a RotatingFile.
+type RotateConfig struct {
+	// Directory of the log files, created if missing; "log" if empty.
+	Dir string
+	// Base name: the current file is Dir/Name.log and rotated ones are
+	// Dir/Name-<timestamp>.log[.gz]; "app" if empty.
+	Name string
+
+	// Rotate once the file would grow past MaxSize bytes; 0 disables.
+	MaxSize int64
+	// Rotate once the file has been written for MaxAge; 0 disables. The age
+	// counts from when the file was opened by this process.
+	MaxAge time.Duration
+	// Number of rotated files to keep; 0 keeps all.
+	MaxBackups int
+	// Gzip rotated files.
+	Compress bool
+}
+
+// RotatingFile is an io.WriteCloser writing to a log file that is rotated by
+// size and age. It is safe for concurrent use; failures are returned from
+// Write, nothing exits the process.
+type RotatingFile struct {
+	cfg RotateConfig
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time
+}
+
+const backupTimeFormat = "20060102T150405.000"
+
+// NewRotatingFile opens (or creates) the current log file of cfg.
+func NewRotatingFile(cfg RotateConfig) (*RotatingFile, error) {
+	if cfg.Dir == "" {
+		cfg.Dir = "log"
+	}
+	if cfg.Name == "" {
+		cfg.Name = "app"
+	}
+	if cfg.MaxSize < 0 || cfg.MaxAge < 0 || cfg.MaxBackups < 0 {
+		return nil, errors.New("rotate: negative limit")
+	}
+
+	f := &RotatingFile{cfg: cfg}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Path returns the path of the current log file.
+func (f *RotatingFile) Path() string {
+	return filepath.Join(f.cfg.Dir, f.cfg.Name+".log")
+}
+
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	tooBig := f.cfg.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.cfg.MaxSize
+	tooOld := f.cfg.MaxAge > 0 && time.Since(f.opened) >= f.cfg.MaxAge
+	if tooBig || tooOld {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate starts a new log file now.
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rotate()
+}
+
+// Close closes the current log file. A later Write opens it again.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(f.cfg.Dir, 0o755); err != nil {
+		return fmt.Errorf("rotate: %w", err)
+	}
+	file, err := os.OpenFile(f.Path(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("rotate: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("rotate: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	f.opened = time.Now()
+	return nil
+}
+
+func (f *RotatingFile) rotate() error {
+	if f.file != nil {
+		if err := f.file.Close(); err != nil {
+			return fmt.Errorf("rotate: %w", err)
+		}
+		f.file = nil
+	}
+
+	if _, err := os.Stat(f.Path()); err == nil {
+		backup := f.backupPath(time.Now())
+		if err := os.Rename(f.Path(), backup); err != nil {
+			return fmt.Errorf("rotate: %w", err)
+		}
+		if f.cfg.Compress {
+			if err := gzipFile(backup); err != nil {
+				return fmt.Errorf("rotate: %w", err)
+			}
+		}
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+	return f.prune()
+}
+
+// backupPath returns an unused name for a file rotated at t.
+func (f *RotatingFile) backupPath(t time.Time) string {
+	base := filepath.Join(f.cfg.Dir, f.cfg.Name+"-"+t.Format(backupTimeFormat))
+	path := base + ".log"
+	for i := 1; exists(path) || exists(path+".gz"); i++ {
+		path = fmt.Sprintf("%s-%d.log", base, i)
+	}
+	return path
+}
+
+// prune removes the oldest rotated files beyond MaxBackups.
+func (f *RotatingFile) prune() error {
+	if f.cfg.MaxBackups == 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(f.cfg.Dir)
+	if err != nil {
+		return fmt.Errorf("rotate: %w", err)
+	}
+
+	type backup struct {
+		name string
+		t    time.Time
+		n    int
+	}
+	var backups []backup
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		if t, n, ok := f.parseBackup(e.Name()); ok {
+			backups = append(backups, backup{e.Name(), t, n})
+		}
+	}
+	if len(backups) <= f.cfg.MaxBackups {
+		return nil
+	}
+
+	// oldest first; Name-<t>-1.log came after Name-<t>.log
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].t.Equal(backups[j].t) {
+			return backups[i].t.Before(backups[j].t)
+		}
+		return backups[i].n < backups[j].n
+	})
+	var first error
+	for _, b := range backups[:len(backups)-f.cfg.MaxBackups] {
+		if err := os.Remove(filepath.Join(f.cfg.Dir, b.name)); err != nil && first == nil {
+			first = fmt.Errorf("rotate: %w", err)
+		}
+	}
+	return first
+}
+
+// parseBackup reports whether name is one of the rotated files written by
+// backupPath, Name-<timestamp>[-<n>].log[.gz], and returns its timestamp
+// and n. Files of another RotatingFile whose Name starts with ours, such as
+// "pos-kitchen-….log" next to "pos", do not match.
+func (f *RotatingFile) parseBackup(name string) (time.Time, int, bool) {
+	rest, ok := strings.CutPrefix(name, f.cfg.Name+"-")
+	if !ok {
+		return time.Time{}, 0, false
+	}
+	rest = strings.TrimSuffix(rest, ".gz")
+	if rest, ok = strings.CutSuffix(rest, ".log"); !ok || len(rest) < len(backupTimeFormat) {
+		return time.Time{}, 0, false
+	}
+	t, err := time.Parse(backupTimeFormat, rest[:len(backupTimeFormat)])
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	n := 0
+	if suffix := rest[len(backupTimeFormat):]; suffix != "" {
+		digits, ok := strings.CutPrefix(suffix, "-")
+		if !ok || strings.Trim(digits, "0123456789") != "" {
+			return time.Time{}, 0, false
+		}
+		if n, err = strconv.Atoi(digits); err != nil || n < 1 {
+			return time.Time{}, 0, false
+		}
+	}
+	return t, n, true
+}
+
+// gzipFile replaces path with path.gz.
+func gzipFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		out.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+	in.Close()
+	return os.Remove(path)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
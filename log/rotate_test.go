@@ -0,0 +1,51 @@
+package printer
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPruneMatchesOwnBackupsOnly(t *testing.T) {
+	dir := t.TempDir()
+	others := []string{
+		"pos-kitchen-20260101T000000.000.log",
+		"pos-kitchen.log",
+		"pos-notes.log",
+		"pos-20260101T000000.000.txt",
+	}
+	ours := []string{
+		"pos-20260101T000000.000.log.gz",
+		"pos-20260101T000000.000-1.log",
+		"pos-20260102T000000.000.log",
+	}
+	for _, name := range append(slices.Clone(others), ours...) {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := NewRotatingFile(RotateConfig{Dir: dir, Name: "pos", MaxBackups: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := f.prune(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name())
+	}
+	want := append(slices.Clone(others), "pos-20260102T000000.000.log", "pos.log")
+	slices.Sort(want)
+	if !slices.Equal(got, want) {
+		t.Errorf("files %v, want %v", got, want)
+	}
+}
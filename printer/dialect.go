@@ -0,0 +1,206 @@
+package printer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AlexStarov/escpos-GoLang-lib/cmd"
+)
+
+// Dialect turns the high-level Printer calls into the bytes of one printer
+// command set. Arguments use the ESC/POS conventions of the cmd package
+// (cmd.Justify*, cmd.Barcode*, ESC p pulse times in 2 ms units); a dialect
+// translates them or returns ErrNotSupported. Turning off a mode the
+// command set does not have is not an error and yields no bytes.
+//
+// Status queries (Check, ReadStatus) and Identify always use ESC/POS.
+type Dialect interface {
+	Init() []byte
+	CodeTable(n byte) []byte
+
+	Align(n byte) ([]byte, error)
+	Emphasize(on bool) ([]byte, error)
+	DoubleStrike(on bool) ([]byte, error)
+	Underline(n byte) ([]byte, error)
+	UpsideDown(on bool) ([]byte, error)
+	Reverse(on bool) ([]byte, error)
+	Smooth(on bool) ([]byte, error)
+	Rotate90(n byte) ([]byte, error)
+	CharSize(width, height int) ([]byte, error)
+	Font(n byte) ([]byte, error)
+	CharSpacing(dots int) ([]byte, error)
+	InternationalCharset(n byte) ([]byte, error)
+	// UserChars switches between downloaded and ROM characters;
+	// DefineUserChar downloads character c, y bytes tall.
+	UserChars(on bool) ([]byte, error)
+	DefineUserChar(c, y byte, ch cmd.UserChar) ([]byte, error)
+	// KanjiMode switches double-byte characters on and off; KanjiCodeSystem
+	// and KanjiPrintMode take the cmd.Kanji* values.
+	KanjiMode(on bool) ([]byte, error)
+	KanjiCodeSystem(n byte) ([]byte, error)
+	KanjiPrintMode(n byte) ([]byte, error)
+
+	FeedLines(n int) ([]byte, error)
+	Position(x int) ([]byte, error)
+	VerticalPosition(y int) ([]byte, error)
+
+	Cut(partial bool) ([]byte, error)
+	Drawer(pin, t1, t2 byte) ([]byte, error)
+
+	// Raster prints a packed 1-bit image, lineWidth bytes per line; mode is
+	// RasterBitImage or RasterGraphics.
+	Raster(width, height, lineWidth int, data []byte, mode string) ([]byte, error)
+	Barcode(m byte, data []byte) ([]byte, error)
+	QRCode(data []byte, size, level byte) ([]byte, error)
+}
+
+// Dialect names of Profile.Dialect and LookupDialect.
+const (
+	DialectEscPos      = "escpos"       // Epson ESC/POS
+	DialectEscPosClone = "escpos-clone" // ESC/POS without GS ( L and GS V m n
+	DialectStarLine    = "star-line"    // Star Line Mode
+	DialectStarPRNT    = "starprnt"     // StarPRNT
+)
+
+// LookupDialect returns the named dialect; "" is DialectEscPos.
+func LookupDialect(name string) (Dialect, error) {
+	switch strings.ToLower(name) {
+	case "", DialectEscPos:
+		return EscPos{}, nil
+	case DialectEscPosClone:
+		return EscPos{NoGraphics: true, CutStyle: CutPlain}, nil
+	case DialectStarLine:
+		return StarLine{}, nil
+	case DialectStarPRNT:
+		return StarPRNT{}, nil
+	}
+	return nil, fmt.Errorf("unknown printer dialect: %s", name)
+}
+
+// CutStyle selects the cut command of EscPos.
+type CutStyle int
+
+const (
+	// CutFeed is GS V m n: feed to the cutter, then cut.
+	CutFeed CutStyle = iota
+	// CutPlain is ESC d n followed by GS V m, for printers without function B.
+	CutPlain
+	// CutLegacy is ESC d n followed by ESC i (full) or ESC m (partial).
+	CutLegacy
+)
+
+// cutFeedLines is the form feed CutPlain and CutLegacy put before the cut,
+// about the distance between print head and cutter.
+const cutFeedLines = 4
+
+// EscPos is the Epson ESC/POS dialect. The fields switch on the quirks of
+// ESC/POS-compatible clones.
+type EscPos struct {
+	// NoGraphics prints every image with GS v 0, for printers that ignore
+	// GS ( L and GS 8 L.
+	NoGraphics bool
+	CutStyle   CutStyle
+}
+
+func (EscPos) Init() []byte            { return cmd.Init() }
+func (EscPos) CodeTable(n byte) []byte { return cmd.CodeTable(n) }
+
+func (EscPos) Align(n byte) ([]byte, error) { return cmd.Justify(n) }
+
+func (EscPos) Emphasize(on bool) ([]byte, error)    { return cmd.Emphasize(on), nil }
+func (EscPos) DoubleStrike(on bool) ([]byte, error) { return cmd.DoubleStrike(on), nil }
+func (EscPos) Underline(n byte) ([]byte, error)     { return cmd.Underline(n) }
+func (EscPos) UpsideDown(on bool) ([]byte, error)   { return cmd.UpsideDown(on), nil }
+func (EscPos) Reverse(on bool) ([]byte, error)      { return cmd.Reverse(on), nil }
+func (EscPos) Smooth(on bool) ([]byte, error)       { return cmd.Smooth(on), nil }
+func (EscPos) Rotate90(n byte) ([]byte, error)      { return cmd.Rotate90(n) }
+
+func (EscPos) CharSize(width, height int) ([]byte, error) { return cmd.CharSize(width, height) }
+func (EscPos) Font(n byte) ([]byte, error)                { return cmd.SelectFont(n) }
+func (EscPos) CharSpacing(dots int) ([]byte, error)       { return cmd.RightSideSpacing(dots) }
+
+func (EscPos) InternationalCharset(n byte) ([]byte, error) { return cmd.InternationalCharset(n) }
+
+func (EscPos) UserChars(on bool) ([]byte, error) { return cmd.UserDefinedChars(on), nil }
+func (EscPos) DefineUserChar(c, y byte, ch cmd.UserChar) ([]byte, error) {
+	return cmd.DefineUserChars(y, c, c, []cmd.UserChar{ch})
+}
+
+func (EscPos) KanjiMode(on bool) ([]byte, error) {
+	if on {
+		return cmd.KanjiMode(), nil
+	}
+	return cmd.CancelKanjiMode(), nil
+}
+func (EscPos) KanjiCodeSystem(n byte) ([]byte, error) { return cmd.KanjiCodeSystem(n) }
+func (EscPos) KanjiPrintMode(n byte) ([]byte, error)  { return cmd.KanjiPrintMode(n) }
+
+func (EscPos) FeedLines(n int) ([]byte, error)        { return cmd.PrintAndFeedLines(n) }
+func (EscPos) Position(x int) ([]byte, error)         { return cmd.AbsolutePosition(x) }
+func (EscPos) VerticalPosition(y int) ([]byte, error) { return cmd.AbsoluteVerticalPosition(y) }
+
+func (e EscPos) Cut(partial bool) ([]byte, error) {
+	mode := byte(cmd.CutFull)
+	if partial {
+		mode = cmd.CutPartial
+	}
+	if e.CutStyle == CutFeed {
+		return cmd.FeedAndCut(mode, 48)
+	}
+
+	out, err := cmd.PrintAndFeedLines(cutFeedLines)
+	if err != nil {
+		return nil, err
+	}
+	if e.CutStyle == CutLegacy {
+		if partial {
+			return append(out, cmd.ESC, 'm'), nil
+		}
+		return append(out, cmd.ESC, 'i'), nil
+	}
+	c, err := cmd.Cut(mode)
+	if err != nil {
+		return nil, err
+	}
+	return append(out, c...), nil
+}
+
+func (EscPos) Drawer(pin, t1, t2 byte) ([]byte, error) { return cmd.Pulse(pin, t1, t2) }
+
+func (e EscPos) Raster(width, height, lineWidth int, data []byte, mode string) ([]byte, error) {
+	if e.NoGraphics {
+		mode = RasterBitImage
+	}
+	switch mode {
+	case RasterBitImage:
+		return cmd.RasterImage(0, lineWidth, height, data)
+	case RasterGraphics:
+		// store each band in the print buffer (fn 112), then print it (fn 50)
+		var out []byte
+		for l := 0; l < height; {
+			lines := min(gs8lMaxY, height-l)
+			b, err := cmd.GraphicsStore(1, 1, width, lines, data[l*lineWidth:(l+lines)*lineWidth])
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, b...)
+			out = append(out, cmd.GraphicsPrint()...)
+			l += lines
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("unknown printing type: %s", mode)
+}
+
+// Barcode sends CODE128 data without an explicit code set prefix as code
+// set B.
+func (EscPos) Barcode(m byte, data []byte) ([]byte, error) {
+	if m == cmd.BarcodeCODE128 && (len(data) == 0 || data[0] != '{') {
+		data = append([]byte("{B"), data...)
+	}
+	return cmd.Barcode(m, data)
+}
+
+func (EscPos) QRCode(data []byte, size, level byte) ([]byte, error) {
+	return cmd.QRCode(data, size, level)
+}
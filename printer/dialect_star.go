@@ -0,0 +1,260 @@
+package printer
+
+import (
+	"fmt"
+
+	"github.com/AlexStarov/escpos-GoLang-lib/cmd"
+)
+
+// Star Line Mode control codes that ESC/POS does not use.
+const (
+	starRS  = 0x1e
+	starSI  = 0x0f
+	starDC2 = 0x12
+	starBEL = 0x07
+	starSUB = 0x1a
+)
+
+// starBarcodes maps the GS k function B systems to Star ESC b n1.
+var starBarcodes = map[byte]byte{
+	cmd.BarcodeUPCE:    0,
+	cmd.BarcodeUPCA:    1,
+	cmd.BarcodeEAN8:    2,
+	cmd.BarcodeEAN13:   3,
+	cmd.BarcodeCODE39:  4,
+	cmd.BarcodeITF:     5,
+	cmd.BarcodeCODE128: 6,
+	cmd.BarcodeCODE93:  7,
+	cmd.BarcodeCODABAR: 8,
+}
+
+// StarLine is the Star Line Mode dialect of Star TSP, SP and mC-Print
+// printers in Star emulation.
+type StarLine struct{}
+
+func (StarLine) Init() []byte            { return []byte{cmd.ESC, '@'} }
+func (StarLine) CodeTable(n byte) []byte { return []byte{cmd.ESC, cmd.GS, 't', n} }
+
+func (StarLine) Align(n byte) ([]byte, error) {
+	if n > cmd.JustifyRight {
+		return nil, fmt.Errorf("invalid justification: %d", n)
+	}
+	return []byte{cmd.ESC, cmd.GS, 'a', n}, nil
+}
+
+func (StarLine) Emphasize(on bool) ([]byte, error) {
+	if on {
+		return []byte{cmd.ESC, 'E'}, nil
+	}
+	return []byte{cmd.ESC, 'F'}, nil
+}
+
+func (StarLine) DoubleStrike(on bool) ([]byte, error) { return starUnsupported(on, "double-strike") }
+
+func (StarLine) Underline(n byte) ([]byte, error) {
+	switch n {
+	case 0, '0':
+		return []byte{cmd.ESC, '-', 0}, nil
+	case 1, 2, '1', '2':
+		// Star has a single underline thickness
+		return []byte{cmd.ESC, '-', 1}, nil
+	}
+	return nil, fmt.Errorf("invalid underline mode: %d", n)
+}
+
+func (StarLine) UpsideDown(on bool) ([]byte, error) {
+	if on {
+		return []byte{starSI}, nil
+	}
+	return []byte{starDC2}, nil
+}
+
+func (StarLine) Reverse(on bool) ([]byte, error) {
+	if on {
+		return []byte{cmd.ESC, '4'}, nil
+	}
+	return []byte{cmd.ESC, '5'}, nil
+}
+
+func (StarLine) Smooth(on bool) ([]byte, error) { return starUnsupported(on, "smoothing") }
+
+func (StarLine) Rotate90(n byte) ([]byte, error) { return starUnsupported(n != 0, "90° rotation") }
+
+// CharSize is ESC i n1 n2; Star magnifies up to 6 times.
+func (StarLine) CharSize(width, height int) ([]byte, error) {
+	if width < 1 || height < 1 || width > 6 || height > 6 {
+		return nil, fmt.Errorf("%w: Star character size %d x %d", ErrNotSupported, width, height)
+	}
+	return []byte{cmd.ESC, 'i', byte(height - 1), byte(width - 1)}, nil
+}
+
+// Font is ESC RS F n; Star models have fonts A and B.
+func (StarLine) Font(n byte) ([]byte, error) {
+	if n > 1 {
+		return nil, fmt.Errorf("%w: Star font %d", ErrNotSupported, n)
+	}
+	return []byte{cmd.ESC, starRS, 'F', n}, nil
+}
+
+// CharSpacing is ESC SP n, up to 15 dots.
+func (StarLine) CharSpacing(dots int) ([]byte, error) {
+	if dots < 0 || dots > 15 {
+		return nil, fmt.Errorf("%w: Star character spacing %d", ErrNotSupported, dots)
+	}
+	return []byte{cmd.ESC, ' ', byte(dots)}, nil
+}
+
+func (StarLine) InternationalCharset(n byte) ([]byte, error) {
+	if n > 15 && n != 64 {
+		return nil, fmt.Errorf("invalid international character set: %d", n)
+	}
+	return []byte{cmd.ESC, 'R', n}, nil
+}
+
+// Star downloads characters in its own bit layout, which Printer does not
+// generate.
+func (StarLine) UserChars(on bool) ([]byte, error) {
+	return starUnsupported(on, "user-defined characters")
+}
+
+func (StarLine) DefineUserChar(c, y byte, ch cmd.UserChar) ([]byte, error) {
+	return nil, fmt.Errorf("%w: Star user-defined characters", ErrNotSupported)
+}
+
+// Star Kanji models have their own double-byte commands, which Printer does
+// not generate.
+func (StarLine) KanjiMode(on bool) ([]byte, error) { return starUnsupported(on, "Kanji mode") }
+
+func (StarLine) KanjiCodeSystem(n byte) ([]byte, error) {
+	return nil, fmt.Errorf("%w: Star Kanji code system", ErrNotSupported)
+}
+
+func (StarLine) KanjiPrintMode(n byte) ([]byte, error) {
+	return starUnsupported(n != 0, "Kanji print modes")
+}
+
+func (StarLine) FeedLines(n int) ([]byte, error) {
+	if n < 1 || n > 127 {
+		return nil, fmt.Errorf("invalid feed: %d lines", n)
+	}
+	return []byte{cmd.ESC, 'a', byte(n)}, nil
+}
+
+// Position is ESC GS A n1 n2.
+func (StarLine) Position(x int) ([]byte, error) {
+	if x < 0 || x > 65535 {
+		return nil, fmt.Errorf("invalid position: %d", x)
+	}
+	return []byte{cmd.ESC, cmd.GS, 'A', byte(x), byte(x >> 8)}, nil
+}
+
+func (StarLine) VerticalPosition(y int) ([]byte, error) {
+	return nil, fmt.Errorf("%w: vertical position in Star Line Mode", ErrNotSupported)
+}
+
+// Cut is ESC d n: feed to the cutter, then cut.
+func (StarLine) Cut(partial bool) ([]byte, error) {
+	if partial {
+		return []byte{cmd.ESC, 'd', 3}, nil
+	}
+	return []byte{cmd.ESC, 'd', 2}, nil
+}
+
+// Drawer sets the pulse with ESC BEL n1 n2 (10 ms units) and fires drawer
+// 1 (BEL) or drawer 2 (SUB).
+func (StarLine) Drawer(pin, t1, t2 byte) ([]byte, error) {
+	fire := byte(starBEL)
+	switch pin {
+	case 0, '0':
+	case 1, '1':
+		fire = starSUB
+	default:
+		return nil, fmt.Errorf("invalid drawer pin: %d", pin)
+	}
+	return []byte{cmd.ESC, starBEL, starPulse(t1), starPulse(t2), fire}, nil
+}
+
+// starPulse converts an ESC/POS pulse time (2 ms units) to Star 10 ms units.
+func starPulse(t byte) byte {
+	n := (int(t)*2 + 9) / 10
+	return byte(max(n, 1))
+}
+
+// Raster prints the image in raster mode: ESC * r A, one "b n1 n2 d..."
+// per line, then ESC * r B. mode is ignored.
+func (StarLine) Raster(width, height, lineWidth int, data []byte, mode string) ([]byte, error) {
+	if len(data) != lineWidth*height {
+		return nil, fmt.Errorf("raster data has %d bytes, want %d", len(data), lineWidth*height)
+	}
+	out := []byte{cmd.ESC, '*', 'r', 'A'}
+	for l := 0; l < height; l++ {
+		out = append(out, 'b', byte(lineWidth), byte(lineWidth>>8))
+		out = append(out, data[l*lineWidth:(l+1)*lineWidth]...)
+	}
+	return append(out, cmd.ESC, '*', 'r', 'B'), nil
+}
+
+// Barcode is ESC b n1 n2 n3 n4 d... RS with the text below the bars, mode 2
+// module width and 80 dots height.
+func (StarLine) Barcode(m byte, data []byte) ([]byte, error) {
+	n1, ok := starBarcodes[m]
+	if !ok {
+		return nil, fmt.Errorf("invalid barcode system: %d", m)
+	}
+	if len(data) == 0 || len(data) > 255 {
+		return nil, fmt.Errorf("invalid barcode data length: %d", len(data))
+	}
+	out := []byte{cmd.ESC, 'b', n1, '2', '2', 80}
+	out = append(out, data...)
+	return append(out, starRS), nil
+}
+
+// QRCode is the ESC GS y sequence: model 2, error correction, cell size
+// (1–8), data and print.
+func (StarLine) QRCode(data []byte, size, level byte) ([]byte, error) {
+	if size < 1 || size > 8 {
+		return nil, fmt.Errorf("%w: Star QR cell size %d", ErrNotSupported, size)
+	}
+	if level < cmd.QRLevelL || level > cmd.QRLevelH {
+		return nil, fmt.Errorf("invalid QR error correction level: %d", level)
+	}
+	if len(data) == 0 || len(data) > 7089 {
+		return nil, fmt.Errorf("invalid QR data length: %d", len(data))
+	}
+	out := []byte{
+		cmd.ESC, cmd.GS, 'y', 'S', '0', 2,
+		cmd.ESC, cmd.GS, 'y', 'S', '1', level - '0',
+		cmd.ESC, cmd.GS, 'y', 'S', '2', size,
+		cmd.ESC, cmd.GS, 'y', 'D', '1', 0, byte(len(data)), byte(len(data) >> 8),
+	}
+	out = append(out, data...)
+	return append(out, cmd.ESC, cmd.GS, 'y', 'P'), nil
+}
+
+// StarPRNT is the StarPRNT dialect of newer Star printers. It shares the
+// Star Line Mode text commands and prints images with ESC GS S.
+type StarPRNT struct {
+	StarLine
+}
+
+// Raster is ESC GS S 1 xL xH yL yH 0 d...; mode is ignored.
+func (StarPRNT) Raster(width, height, lineWidth int, data []byte, mode string) ([]byte, error) {
+	if len(data) != lineWidth*height {
+		return nil, fmt.Errorf("raster data has %d bytes, want %d", len(data), lineWidth*height)
+	}
+	if lineWidth > 65535 || height > 65535 {
+		return nil, fmt.Errorf("raster image too large: %d x %d", width, height)
+	}
+	out := []byte{cmd.ESC, cmd.GS, 'S', 1,
+		byte(lineWidth), byte(lineWidth >> 8), byte(height), byte(height >> 8), 0}
+	return append(out, data...), nil
+}
+
+// starUnsupported turns an unsupported mode off silently and refuses to
+// turn it on.
+func starUnsupported(on bool, what string) ([]byte, error) {
+	if on {
+		return nil, fmt.Errorf("%w: %s in Star mode", ErrNotSupported, what)
+	}
+	return nil, nil
+}
@@ -0,0 +1,253 @@
+package printer
+
+import (
+	"context"
+	"fmt"
+)
+
+// CommandType names a Document command.
+type CommandType string
+
+const (
+	CmdText      CommandType = "text"
+	CmdParagraph CommandType = "paragraph"
+	CmdStyle     CommandType = "style"
+	CmdAlign     CommandType = "align"
+	CmdFeed      CommandType = "feed"
+	CmdCut       CommandType = "cut"
+	CmdRaster    CommandType = "raster"
+	CmdBarcode   CommandType = "barcode"
+	CmdPulse     CommandType = "pulse"
+)
+
+// Style attributes accepted by a CmdStyle command. StyleSize uses Width and
+// Height, the others use Value.
+const (
+	StyleUnderline    = "underline"
+	StyleEmphasize    = "emphasize"
+	StyleDoubleStrike = "double_strike"
+	StyleUpsidedown   = "upsidedown"
+	StyleRotate       = "rotate"
+	StyleReverse      = "reverse"
+	StyleSmooth       = "smooth"
+	StyleSize         = "size"
+	StyleFont         = "font"    // 0, 1 or 2 for font A, B or C
+	StyleSpacing      = "spacing" // dots right of each character
+)
+
+// Command is a single step of a Document. Only the fields used by Type are
+// set; the rest stay zero and are omitted from JSON.
+type Command struct {
+	Type CommandType `json:"type"`
+
+	// CmdText and CmdParagraph
+	Text string `json:"text,omitempty"`
+
+	// CmdStyle
+	Attr  string `json:"attr,omitempty"`
+	Value byte   `json:"value,omitempty"`
+
+	// CmdStyle (size) and CmdRaster, in characters and dots respectively
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+
+	// CmdAlign: left, center or right
+	Align string `json:"align,omitempty"`
+
+	// CmdFeed
+	Lines int `json:"lines,omitempty"`
+
+	// CmdRaster: packed 1-bit rows, bytes per row and "bitImage" or "graphics"
+	Data      []byte `json:"data,omitempty"`
+	LineWidth int    `json:"line_width,omitempty"`
+	Mode      string `json:"mode,omitempty"`
+
+	// CmdBarcode
+	Symbology string `json:"symbology,omitempty"`
+	Code      string `json:"code,omitempty"`
+}
+
+// Document is a receipt kept as data. It compiles to ESC/POS bytes without
+// a printer and round-trips through encoding/json.
+type Document struct {
+	Commands []Command `json:"commands"`
+}
+
+// NewDocument returns an empty Document.
+func NewDocument() *Document {
+	return &Document{}
+}
+
+func (d *Document) add(c Command) *Document {
+	d.Commands = append(d.Commands, c)
+	return d
+}
+
+// Text appends s as is.
+func (d *Document) Text(s string) *Document {
+	return d.add(Command{Type: CmdText, Text: s})
+}
+
+// Paragraph appends s word-wrapped and aligned, see Printer.Paragraph.
+func (d *Document) Paragraph(s string) *Document {
+	return d.add(Command{Type: CmdParagraph, Text: s})
+}
+
+// Style sets one of the Style* attributes other than StyleSize to v.
+func (d *Document) Style(attr string, v byte) *Document {
+	return d.add(Command{Type: CmdStyle, Attr: attr, Value: v})
+}
+
+// FontSize sets the character magnification, 1–8 in each direction.
+func (d *Document) FontSize(width, height int) *Document {
+	return d.add(Command{Type: CmdStyle, Attr: StyleSize, Width: width, Height: height})
+}
+
+func (d *Document) Align(align string) *Document {
+	return d.add(Command{Type: CmdAlign, Align: align})
+}
+
+// Feed prints the buffer and feeds n lines.
+func (d *Document) Feed(n int) *Document {
+	return d.add(Command{Type: CmdFeed, Lines: n})
+}
+
+func (d *Document) Cut() *Document {
+	return d.add(Command{Type: CmdCut})
+}
+
+// Raster appends a packed black and white image, see Printer.Raster.
+func (d *Document) Raster(width, height, lineWidth int, data []byte, mode string) *Document {
+	return d.add(Command{
+		Type:      CmdRaster,
+		Width:     width,
+		Height:    height,
+		LineWidth: lineWidth,
+		Data:      data,
+		Mode:      mode,
+	})
+}
+
+func (d *Document) Barcode(symbology, code string) *Document {
+	return d.add(Command{Type: CmdBarcode, Symbology: symbology, Code: code})
+}
+
+func (d *Document) Pulse() *Document {
+	return d.add(Command{Type: CmdPulse})
+}
+
+// Compile returns the ESC/POS bytes of the document for DefaultProfile.
+func (d *Document) Compile() ([]byte, error) {
+	return d.CompileFor(DefaultProfile())
+}
+
+// CompileFor returns the bytes of the document for a printer with the given
+// profile, in the profile's dialect.
+func (d *Document) CompileFor(profile *Profile) ([]byte, error) {
+	return d.compile(WithProfile(profile))
+}
+
+func (d *Document) compile(opts ...Option) ([]byte, error) {
+	var buf BufferTransport
+	p, err := NewPrinter(&buf, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.applyAll(d); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Print prints doc as a single write, with p's own code pages, fallback,
+// user characters and formatting state, as if its commands had been
+// called on p one by one.
+func (p *Printer) Print(doc *Document) error {
+	return p.PrintContext(context.Background(), doc)
+}
+
+// PrintContext is Print bounded by ctx. The document is sent as a Job.
+func (p *Printer) PrintContext(ctx context.Context, doc *Document) error {
+	return p.JobContext(ctx, func(j *Job) error {
+		return j.applyAll(doc)
+	})
+}
+
+func (p *Printer) applyAll(d *Document) error {
+	for i, c := range d.Commands {
+		if err := p.apply(c); err != nil {
+			return fmt.Errorf("command %d (%s): %w", i, c.Type, err)
+		}
+	}
+	return nil
+}
+
+func (p *Printer) apply(c Command) error {
+	switch c.Type {
+	case CmdText:
+		return p.text(c.Text)
+
+	case CmdParagraph:
+		return p.paragraph(c.Text)
+
+	case CmdStyle:
+		return p.applyStyle(c)
+
+	case CmdAlign:
+		return p.setAlign(c.Align)
+
+	case CmdFeed:
+		if c.Lines < 0 || c.Lines > 255 {
+			return fmt.Errorf("invalid feed lines: %d", c.Lines)
+		}
+		return p.formfeedN(c.Lines)
+
+	case CmdCut:
+		return p.cut()
+
+	case CmdRaster:
+		if c.LineWidth <= 0 || c.Height <= 0 || len(c.Data) < c.LineWidth*c.Height {
+			return fmt.Errorf("raster data does not match %d x %d", c.LineWidth, c.Height)
+		}
+		return p.raster(c.Width, c.Height, c.LineWidth, c.Data, c.Mode)
+
+	case CmdBarcode:
+		return p.barcode(c.Symbology, c.Code)
+
+	case CmdPulse:
+		return p.pulse()
+	}
+	return fmt.Errorf("unknown command type: %q", c.Type)
+}
+
+func (p *Printer) applyStyle(c Command) error {
+	switch c.Attr {
+	case StyleUnderline:
+		return p.setUnderline(c.Value)
+	case StyleEmphasize:
+		return p.setEmphasize(c.Value)
+	case StyleDoubleStrike:
+		return p.setDoubleStrike(c.Value)
+	case StyleUpsidedown:
+		return p.setUpsidedown(c.Value)
+	case StyleRotate:
+		return p.setRotate90(c.Value)
+	case StyleReverse:
+		return p.setReverse(c.Value)
+	case StyleSmooth:
+		return p.setSmooth(c.Value)
+	case StyleFont:
+		if c.Value > 2 {
+			return fmt.Errorf("invalid font: %d", c.Value)
+		}
+		return p.setFont(string(rune('A' + c.Value)))
+	case StyleSpacing:
+		return p.setCharSpacing(c.Value)
+	case StyleSize:
+		if c.Width < 1 || c.Width > 8 || c.Height < 1 || c.Height > 8 {
+			return fmt.Errorf("invalid font size passed: %d x %d", c.Width, c.Height)
+		}
+		return p.setFontSize(byte(c.Width), byte(c.Height))
+	}
+	return fmt.Errorf("unknown style attribute: %q", c.Attr)
+}
@@ -0,0 +1,75 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestDocumentCompile(t *testing.T) {
+	doc := NewDocument().
+		Style(StyleEmphasize, 1).
+		Text("Total\n").
+		Barcode("ean8", "96385074").
+		Cut()
+	got, err := doc.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, w := newTestPrinter(t, "default")
+	if err := p.SetEmphasize(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Text("Total\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Barcode("ean8", "96385074"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Cut(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, w.Bytes()) {
+		t.Errorf("compiled % x, printed % x", got, w.Bytes())
+	}
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back Document
+	if err := json.Unmarshal(b, &back); err != nil {
+		t.Fatal(err)
+	}
+	again, err := back.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, got) {
+		t.Errorf("after JSON % x, want % x", again, got)
+	}
+}
+
+func TestPrintKeepsPrinterState(t *testing.T) {
+	p, w := newTestPrinter(t, "epson-tm-t20", WithTextFallback(func(r rune) string {
+		if r == '♔' {
+			return "KING"
+		}
+		return ""
+	}))
+	if err := p.Print(NewDocument().Style(StyleEmphasize, 1).Text("5♔\n")); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(w.Bytes(), []byte("KING\n")) {
+		t.Errorf("printed % x, want the text fallback", w.Bytes())
+	}
+
+	w.Reset()
+	if err := p.SetEmphasize(0); err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0x1b, 'E', 0}; !bytes.Equal(w.Bytes(), want) {
+		t.Errorf("SetEmphasize(0) after Print sent % x, want % x", w.Bytes(), want)
+	}
+}
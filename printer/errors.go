@@ -0,0 +1,98 @@
+package printer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+
+	"go.bug.st/serial"
+)
+
+// Printer conditions. Match them with errors.Is; transport errors carry the
+// matching condition as a TransportError.
+var (
+	ErrPaperOut     = errors.New("printer: paper out")
+	ErrCoverOpen    = errors.New("printer: cover open")
+	ErrOffline      = errors.New("printer: offline")
+	ErrCutterJam    = errors.New("printer: cutter jam")
+	ErrTimeout      = errors.New("printer: timeout")
+	ErrNotSupported = errors.New("printer: not supported")
+)
+
+// TransportError is a failed read or write on the connection to the
+// printer. Kind is ErrTimeout, ErrOffline or nil if the cause is unknown;
+// Err is the original error from the connection.
+type TransportError struct {
+	Op   string // "read" or "write"
+	Kind error
+	Err  error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("printer %s: %v", e.Op, e.Err)
+}
+
+func (e *TransportError) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// LPDError is an LPD server refusing or failing to acknowledge a stage of
+// the job. NAK is the byte the server sent instead of 0x00; it is only
+// meaningful if Err is nil.
+type LPDError struct {
+	Stage string
+	NAK   byte
+	Err   error
+}
+
+func (e *LPDError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("LPD: reading ACK on %s: %v", e.Stage, e.Err)
+	}
+	return fmt.Sprintf("LPD: request not acknowledged on %s (0x%02x)", e.Stage, e.NAK)
+}
+
+func (e *LPDError) Unwrap() error {
+	return e.Err
+}
+
+// transportError wraps err from the connection as a TransportError, leaving
+// nil, io.EOF and errors that already are TransportErrors alone.
+func transportError(op string, err error) error {
+	if err == nil || err == io.EOF {
+		return err
+	}
+	var te *TransportError
+	if errors.As(err, &te) {
+		return err
+	}
+	return &TransportError{Op: op, Kind: errorKind(err), Err: err}
+}
+
+// errorKind maps a connection error onto ErrTimeout or ErrOffline.
+func errorKind(err error) error {
+	var ne net.Error
+	var pe *serial.PortError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, os.ErrDeadlineExceeded),
+		errors.As(err, &ne) && ne.Timeout():
+		return ErrTimeout
+	case errors.Is(err, net.ErrClosed),
+		errors.Is(err, io.ErrClosedPipe),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.EPIPE),
+		errors.Is(err, syscall.ENODEV),
+		errors.As(err, &pe) && pe.Code() == serial.PortClosed:
+		return ErrOffline
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package printer
+
+import (
+	"bytes"
+	"testing"
+)
+
+// bufPrinter is an io.ReadWriter that keeps what is written and has
+// nothing to read.
+type bufPrinter struct {
+	bytes.Buffer
+}
+
+func (b *bufPrinter) Read(p []byte) (int, error) {
+	return 0, nil
+}
+
+// newTestPrinter returns a Printer on the named profile writing to a buffer.
+func newTestPrinter(t *testing.T, profile string, opts ...Option) (*Printer, *bufPrinter) {
+	t.Helper()
+	prof, err := LookupProfile(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &bufPrinter{}
+	p, err := NewPrinter(w, append([]Option{WithProfile(prof)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p, w
+}
@@ -0,0 +1,256 @@
+package printer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AlexStarov/escpos-GoLang-lib/cmd"
+)
+
+// PrinterInfo is what a printer reports about itself through GS I.
+type PrinterInfo struct {
+	ModelID byte // GS I 1
+	TypeID  byte // GS I 2
+
+	Firmware     string // GS I 65
+	Manufacturer string // GS I 66
+	Model        string // GS I 67
+	Serial       string // GS I 68
+
+	// name of the matching capability profile, empty if none matched
+	Profile string
+}
+
+// errNoReply is returned by Identify when the printer answers no GS I query.
+var errNoReply = errors.New("printer did not answer GS I")
+
+// drainTimeout is how long drain waits for more stale input.
+const drainTimeout = 20 * time.Millisecond
+
+// Identify queries the printer with GS I and, if the reported manufacturer
+// and model match an embedded profile, switches the printer to it and its
+// dialect. The paper width, the code pages added by WithCodeTable or
+// AddCodePage, the Kanji encoding and the WithCodePage page are kept; if
+// the new profile lacks that page, the printer keeps its profile and the
+// error is returned with the info.
+//
+// Each query waits up to statusTimeout for a reply; the first one left
+// unanswered ends the queries, leaving the remaining fields empty. An error
+// is returned if ctx ends, the connection fails, the printer answers none
+// of the queries, or it is reached over LPD, which never returns replies.
+func (p *Printer) Identify(ctx context.Context) (*PrinterInfo, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if _, ok := p.t.(*LPDTransport); ok {
+		return nil, fmt.Errorf("%w: Identify over LPD", ErrNotSupported)
+	}
+
+	info := &PrinterInfo{}
+	answered := false
+
+	for _, q := range []struct {
+		n    byte
+		id   *byte
+		text *string
+	}{
+		{1, &info.ModelID, nil},
+		{2, &info.TypeID, nil},
+		{65, nil, &info.Firmware},
+		{66, nil, &info.Manufacturer},
+		{67, nil, &info.Model},
+		{68, nil, &info.Serial},
+	} {
+		b, err := p.queryID(ctx, q.n, q.text != nil)
+		if errors.Is(err, errNoReply) {
+			// a printer that skips one query mostly skips the rest too, so
+			// do not wait for each of them
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if q.text != nil {
+			*q.text = strings.TrimSpace(string(b))
+		} else if len(b) > 0 {
+			*q.id = b[0]
+		}
+		answered = true
+	}
+
+	if !answered {
+		return nil, errNoReply
+	}
+
+	if prof := matchProfile(info.Manufacturer, info.Model); prof != nil {
+		if err := p.adoptProfile(prof); err != nil {
+			return info, err
+		}
+		info.Profile = prof.Name
+	}
+	return info, nil
+}
+
+// adoptProfile switches the printer to the detected profile prof and its
+// dialect, keeping what was set up for this printer rather than its model:
+// the paper width, the code pages added by WithCodeTable or AddCodePage,
+// the Kanji encoding and the code page of WithCodePage, which must exist in
+// prof. On error nothing changes. The caller holds the lock.
+func (p *Printer) adoptProfile(prof *Profile) error {
+	next := prof.clone()
+	if cur := p.profile; cur.DotWidth > 0 && next.DotWidth > 0 && cur.paperWidth() != next.paperWidth() {
+		var err error
+		if next, err = next.WithPaperWidth(cur.paperWidth()); err != nil {
+			return err
+		}
+	}
+	// pages the current profile has beyond its embedded one were added
+	base := profiles[p.profile.Name]
+	for _, cp := range p.profile.CodePages {
+		if base == nil || !slices.Contains(base.CodePages, cp) {
+			next.AddCodePage(cp.Name, cp.ID)
+		}
+	}
+	if p.profile.Kanji != "" {
+		next.Kanji = p.profile.Kanji
+	}
+
+	fixed := p.fixedCodePage
+	if p.hasCodePage {
+		name := ""
+		for _, cp := range p.profile.CodePages {
+			if cp.ID == p.fixedCodePage {
+				name = cp.Name
+			}
+		}
+		id, ok := next.CodePage(name)
+		if !ok {
+			return fmt.Errorf("%s has no code page %s", next.Name, name)
+		}
+		fixed = id
+	}
+
+	dialect, err := LookupDialect(next.Dialect)
+	if err != nil {
+		return err
+	}
+	// the device state was tracked with the old profile and command set
+	p.device, p.userSlots = unknownStyle, nil
+	p.profile, p.dialect = next, dialect
+	if p.hasCodePage && fixed != p.fixedCodePage {
+		p.fixedCodePage = fixed
+		p.style.codePage = int(fixed)
+	}
+	return nil
+}
+
+// queryID sends GS I n and reads the reply: a single byte for n < 64, or a
+// "_" header, the text and a NUL terminator for the info block. Input left
+// from earlier, such as a reply that came after its query timed out, is
+// discarded first. It returns errNoReply if the reply does not come within
+// statusTimeout.
+func (p *Printer) queryID(ctx context.Context, n byte, block bool) ([]byte, error) {
+	b, err := cmd.TransmitPrinterID(n)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.drain(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := p.send(ctx, b); err != nil {
+		return nil, err
+	}
+
+	qctx, cancel := context.WithTimeout(ctx, statusTimeout)
+	defer cancel()
+
+	var out []byte
+	started := !block
+	buf := make([]byte, 1)
+	for {
+		r, err := p.t.ReadContext(qctx, buf)
+		if err != nil {
+			if (qctx.Err() != nil || errors.Is(err, ErrTimeout)) && ctx.Err() == nil {
+				return nil, errNoReply
+			}
+			return nil, err
+		}
+		if r == 0 {
+			continue
+		}
+		switch {
+		case !started:
+			// skip anything before the header, e.g. automatic status
+			started = buf[0] == '_'
+		case !block:
+			return buf, nil
+		case buf[0] == 0x00:
+			return out, nil
+		default:
+			out = append(out, buf[0])
+		}
+		if len(out) > 80 {
+			return nil, fmt.Errorf("GS I %d reply is not terminated", n)
+		}
+	}
+}
+
+// drain discards what the printer has already sent, reading until nothing
+// more comes within drainTimeout.
+func (p *Printer) drain(ctx context.Context) error {
+	buf := make([]byte, 64)
+	for range 64 {
+		dctx, cancel := context.WithTimeout(ctx, drainTimeout)
+		n, err := p.t.ReadContext(dctx, buf)
+		timedOut := dctx.Err() != nil || errors.Is(err, ErrTimeout)
+		cancel()
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case err != nil && timedOut:
+			return nil
+		case err != nil:
+			return err
+		case n == 0:
+			// serial ports return 0 bytes on their own read timeout
+			return nil
+		}
+	}
+	return nil
+}
+
+// matchProfile finds the profile whose vendor equals manufacturer and whose
+// model is the longest prefix of model, ignoring case, spaces and dashes.
+func matchProfile(manufacturer, model string) *Profile {
+	norm := func(s string) string {
+		if i := strings.Index(s, "("); i >= 0 {
+			s = s[:i]
+		}
+		return strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToUpper(s))
+	}
+
+	var best *Profile
+	m := norm(model)
+	for _, prof := range profiles {
+		if prof.Dialect == DialectStarLine || prof.Dialect == DialectStarPRNT {
+			// a printer that answers GS I is in ESC/POS mode
+			continue
+		}
+		if prof.Kanji != "" {
+			// regional models differ in their font ROM, which GS I does not report
+			continue
+		}
+		pm := norm(prof.Model)
+		if pm == "" || !strings.EqualFold(prof.Vendor, strings.TrimSpace(manufacturer)) {
+			continue
+		}
+		if strings.HasPrefix(m, pm) && (best == nil || len(pm) > len(norm(best.Model))) {
+			best = prof
+		}
+	}
+	return best
+}
@@ -0,0 +1,94 @@
+package printer
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/AlexStarov/escpos-GoLang-lib/cmd"
+	"github.com/AlexStarov/escpos-GoLang-lib/codepage"
+)
+
+// gsIPrinter answers the GS I queries sent to conn as an Xprinter XP-80.
+func gsIPrinter(conn net.Conn) {
+	replies := map[byte]string{
+		1:  "\x20",
+		2:  "\x02",
+		65: "_1.0\x00",
+		66: "_Xprinter\x00",
+		67: "_XP-80\x00",
+		68: "_X123\x00",
+	}
+	q := make([]byte, 3)
+	for {
+		if _, err := io.ReadFull(conn, q); err != nil {
+			return
+		}
+		if q[0] != cmd.GS || q[1] != 'I' {
+			continue
+		}
+		if _, err := conn.Write([]byte(replies[q[2]])); err != nil {
+			return
+		}
+	}
+}
+
+func identify(t *testing.T, opts ...Option) (*Printer, *PrinterInfo, error) {
+	t.Helper()
+	conn, printer := net.Pipe()
+	t.Cleanup(func() { conn.Close(); printer.Close() })
+	go gsIPrinter(printer)
+	p, err := NewPrinter(conn, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := p.Identify(context.Background())
+	return p, info, err
+}
+
+func TestIdentifyAdoptsProfile(t *testing.T) {
+	narrow, err := DefaultProfile().WithPaperWidth(58)
+	if err != nil {
+		t.Fatal(err)
+	}
+	georgian := codepage.FromMap("Georgian", map[byte]rune{0x80: 'ა'})
+	p, info, err := identify(t, WithProfile(narrow), WithCodeTable(georgian, 99), WithCodePage("CP866"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Manufacturer != "Xprinter" || info.Model != "XP-80" || info.Serial != "X123" {
+		t.Errorf("info %+v", info)
+	}
+	prof := p.Profile()
+	if info.Profile != "xprinter-xp-80" || prof.Name != "xprinter-xp-80" {
+		t.Fatalf("profile %s, info %s, want xprinter-xp-80", prof.Name, info.Profile)
+	}
+	if prof.DotWidth != 384 {
+		t.Errorf("dot width %d, want the 58mm 384", prof.DotWidth)
+	}
+	if id, ok := prof.CodePage("Georgian"); !ok || id != 99 {
+		t.Errorf("Georgian is %d, %v, want 99", id, ok)
+	}
+	if d, ok := p.Dialect().(EscPos); !ok || !d.NoGraphics {
+		t.Errorf("dialect %#v, want the ESC/POS clone", p.Dialect())
+	}
+}
+
+func TestIdentifyKeepsProfileWithoutFixedPage(t *testing.T) {
+	prof, err := LookupProfile("epson-tm-t20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, info, err := identify(t, WithProfile(prof), WithCodePage("Katakana"))
+	if err == nil || !strings.Contains(err.Error(), "no code page Katakana") {
+		t.Fatalf("error %v, want the missing Katakana page", err)
+	}
+	if info == nil || info.Model != "XP-80" {
+		t.Errorf("info %+v, want the reported one", info)
+	}
+	if p.Profile().Name != "epson-tm-t20" {
+		t.Errorf("profile %s, want epson-tm-t20 kept", p.Profile().Name)
+	}
+}
@@ -0,0 +1,105 @@
+package printer
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+)
+
+// Job is a print job under construction. It has the full Printer command
+// set, but commands only go to an in-memory buffer; see Printer.Job.
+type Job struct {
+	*Printer
+	buf BufferTransport
+}
+
+// Job locks the printer, runs fn and sends everything fn produced as one
+// write. Other goroutines printing to p wait until the job is sent. If fn
+// returns an error nothing is sent and the printer state is left unchanged.
+//
+// fn must print through j only: p stays locked while fn runs, so calling a
+// method of p from fn deadlocks.
+func (p *Printer) Job(fn func(j *Job) error) error {
+	return p.JobContext(context.Background(), fn)
+}
+
+// JobContext is Job with the final write bounded by ctx.
+func (p *Printer) JobContext(ctx context.Context, fn func(j *Job) error) error {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.err != nil {
+		return p.err
+	}
+
+	p.jobs++
+	log := p.logger.With("job", p.jobs)
+
+	info := JobInfo{ID: p.jobs, Start: time.Now()}
+	for _, h := range p.jobHooks {
+		if h.OnJobStart != nil {
+			h.OnJobStart(ctx, info)
+		}
+	}
+	err := p.runJob(ctx, fn, &info)
+	info.Duration = time.Since(info.Start)
+	p.metrics.jobDone(p.name, info.Duration, err)
+	for _, h := range p.jobHooks {
+		if h.OnJobEnd != nil {
+			h.OnJobEnd(ctx, info, err)
+		}
+	}
+	if err != nil {
+		log.Debug("job failed", "error", err, "duration", info.Duration)
+		return err
+	}
+	log.Debug("job sent", "bytes", info.Bytes, "duration", info.Duration)
+	return nil
+}
+
+// runJob builds and sends a job; the caller holds the lock.
+func (p *Printer) runJob(ctx context.Context, fn func(j *Job) error, info *JobInfo) error {
+	j := &Job{}
+	j.Printer = &Printer{
+		t:             &j.buf,
+		profile:       p.profile,
+		dialect:       p.dialect,
+		fixedCodePage: p.fixedCodePage,
+		hasCodePage:   p.hasCodePage,
+		fallback:      p.fallback,
+		codeTables:    p.codeTables,
+		kanji:         p.kanji,
+		userGlyphs:    p.userGlyphs,
+		logger:        p.logger,
+	}
+	j.copyStyle(p)
+
+	if err := fn(j); err != nil {
+		return err
+	}
+
+	n, err := p.send(ctx, j.buf.Bytes())
+	info.Bytes = n
+	if err != nil {
+		// part of the job may have reached the printer
+		p.device, p.userSlots = unknownStyle, nil
+		return err
+	}
+	p.copyStyle(j.Printer)
+	return nil
+}
+
+// Bytes returns the commands buffered so far.
+func (j *Job) Bytes() []byte {
+	return j.buf.Bytes()
+}
+
+// copyStyle takes over the formatting state of src, both requested and
+// known on the device, its SaveState stack and its downloaded characters.
+func (p *Printer) copyStyle(src *Printer) {
+	p.style = src.style
+	p.device = src.device
+	p.saved = slices.Clone(src.saved)
+	p.userSlots = maps.Clone(src.userSlots)
+}
@@ -0,0 +1,114 @@
+package printer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+
+	"github.com/AlexStarov/escpos-GoLang-lib/cmd"
+)
+
+// Double-byte encodings of Profile.Kanji and WithKanji.
+const (
+	KanjiShiftJIS = "shift-jis" // Japanese
+	KanjiGB18030  = "gb18030"   // Simplified Chinese
+	KanjiBig5     = "big5"      // Traditional Chinese
+	KanjiEUCKR    = "euc-kr"    // Korean, KS C 5601
+)
+
+var kanjiEncodings = map[string]encoding.Encoding{
+	KanjiShiftJIS: japanese.ShiftJIS,
+	KanjiGB18030:  simplifiedchinese.GB18030,
+	KanjiBig5:     traditionalchinese.Big5,
+	KanjiEUCKR:    korean.EUCKR,
+}
+
+// lookupKanji returns the named double-byte encoding; "" is none.
+func lookupKanji(name string) (encoding.Encoding, error) {
+	if name == "" {
+		return nil, nil
+	}
+	enc, ok := kanjiEncodings[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown Kanji encoding: %s", name)
+	}
+	return enc, nil
+}
+
+// textKanji appends to buf the run of characters at the start of runes that
+// no code page has but the Kanji encoding of the printer does, between FS &
+// and FS ., and returns how many characters it took. ASCII inside the run
+// stays in Kanji mode, where it prints as single bytes. FS C selects the
+// code system only for Shift-JIS: Japanese models have both JIS and
+// Shift-JIS, while the Chinese, Taiwanese and Korean ones have one fixed
+// encoding in ROM.
+func (p *Printer) textKanji(buf []byte, runes []rune, pages []textCodePage) ([]byte, int, error) {
+	if p.kanji == nil {
+		return buf, 0, nil
+	}
+	on, err := p.dialect.KanjiMode(true)
+	if err != nil {
+		return buf, 0, nil
+	}
+	off, _ := p.dialect.KanjiMode(false)
+
+	enc := p.kanji.NewEncoder()
+	var text []byte
+	n := 0
+	for _, r := range runes {
+		if r < utf8.RuneSelf {
+			text = append(text, byte(r))
+			n++
+			continue
+		}
+		if pickCodePage(pages, []rune{r}) >= 0 {
+			break
+		}
+		b, err := enc.Bytes(utf8.AppendRune(nil, r))
+		if err != nil || !(len(b) == 2 || len(b) == 4 && unicode.Is(unicode.Han, r)) {
+			// GB18030 has four-byte codes for all of Unicode, but Kanji
+			// fonts only go beyond the double-byte set for ideographs
+			break
+		}
+		text = append(text, b...)
+		n++
+	}
+	// trailing ASCII is left to the single-byte code page
+	for n > 0 && runes[n-1] < utf8.RuneSelf {
+		n--
+		text = text[:len(text)-1]
+	}
+	if n == 0 {
+		return buf, 0, nil
+	}
+
+	buf = append(buf, on...)
+	if strings.EqualFold(p.profile.Kanji, KanjiShiftJIS) {
+		b, err := p.dialect.KanjiCodeSystem(cmd.KanjiShiftJIS)
+		if err != nil {
+			return buf, 0, err
+		}
+		buf = append(buf, b...)
+	}
+	// FS ! keeps Kanji underlined with the rest of the text; GS ! sizes and
+	// ESC E already apply to Kanji
+	var mode byte
+	if p.underline != 0 && p.underline != '0' {
+		mode = cmd.KanjiUnderline
+	}
+	b, err := p.dialect.KanjiPrintMode(mode)
+	if err != nil {
+		return buf, 0, err
+	}
+	buf = append(buf, b...)
+	buf = append(buf, text...)
+	buf = append(buf, off...)
+	return buf, n, nil
+}
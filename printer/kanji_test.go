@@ -0,0 +1,71 @@
+package printer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/AlexStarov/escpos-GoLang-lib/cmd"
+)
+
+func TestInitCancelsKanjiMode(t *testing.T) {
+	tests := []struct {
+		profile string
+		want    []byte
+	}{
+		// Chinese models power up in Kanji mode and ESC @ leaves it on
+		{"epson-tm-t88-cn", []byte{cmd.ESC, '@', cmd.FS, '.'}},
+		{"epson-tm-t20", []byte{cmd.ESC, '@'}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			p, w := newTestPrinter(t, tt.profile)
+			if err := p.Init(); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(w.Bytes(), tt.want) {
+				t.Errorf("Init sent % x, want % x", w.Bytes(), tt.want)
+			}
+		})
+	}
+}
+
+func TestKanjiModeTracked(t *testing.T) {
+	p, w := newTestPrinter(t, "epson-tm-t88-cn")
+	if err := p.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	w.Reset()
+	if err := p.Text("中文"); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(w.Bytes(), []byte{cmd.FS, '&'}) || !bytes.HasSuffix(w.Bytes(), []byte{cmd.FS, '.'}) {
+		t.Errorf("Text sent % x, want it between FS & and FS .", w.Bytes())
+	}
+
+	// Resync forgets the device state, so Kanji mode is cancelled again
+	w.Reset()
+	if err := p.Resync(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasSuffix(w.Bytes(), []byte{cmd.FS, '.'}) {
+		t.Errorf("Resync sent % x, want FS . last", w.Bytes())
+	}
+}
+
+func TestWriteRawCancelsKanjiMode(t *testing.T) {
+	for _, profile := range []string{"epson-tm-t20", "epson-tm-t88-cn"} {
+		t.Run(profile, func(t *testing.T) {
+			p, w := newTestPrinter(t, profile)
+			if err := p.WriteRaw([]byte{cmd.FS, '&', 0xd6, 0xd0}); err != nil {
+				t.Fatal(err)
+			}
+			b := w.Bytes()
+			on := bytes.Index(b, []byte{cmd.FS, '&'})
+			off := bytes.LastIndex(b, []byte{cmd.FS, '.'})
+			if on < 0 || off < on {
+				t.Errorf("WriteRaw sent % x, want FS . after FS &", b)
+			}
+		})
+	}
+}
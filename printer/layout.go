@@ -0,0 +1,209 @@
+package printer
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+
+	"github.com/AlexStarov/escpos-GoLang-lib/cmd"
+)
+
+// runeWidth returns the columns r takes on the paper: 0 for combining marks
+// and other zero-width characters, 2 for East Asian wide characters, which
+// print in the double-width Kanji font, and 1 for the rest.
+func runeWidth(r rune) int {
+	if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// TextWidth returns the columns s takes on the paper at normal character
+// size. Unlike len or utf8.RuneCountInString it counts a Cyrillic letter
+// as one column, a Kanji as two and a combining accent as none.
+func TextWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		n += runeWidth(r)
+	}
+	return n
+}
+
+// Wrap breaks s into lines at most cols columns wide (see TextWidth). Lines
+// break at spaces, tabs and no-break spaces where possible; a word longer
+// than a line starts on a line of its own and is broken inside, never
+// between a character and its combining marks. "\n" in s ends a line. A tab
+// becomes a space; breaks at the end of a line are dropped, others are
+// kept.
+func Wrap(s string, cols int) []string {
+	w := wrapper{cols: max(cols, 1)}
+	s = strings.ReplaceAll(s, "\t", " ")
+	for _, para := range strings.Split(s, "\n") {
+		for para != "" {
+			word := strings.TrimLeftFunc(para, isBreak)
+			sep := para[:len(para)-len(word)]
+			if i := strings.IndexFunc(word, isBreak); i >= 0 {
+				word = word[:i]
+			}
+			para = para[len(sep)+len(word):]
+			if word != "" {
+				w.word(sep, word)
+			}
+		}
+		w.flush()
+	}
+	return w.lines
+}
+
+// isBreak reports whether Wrap may break a line at r.
+func isBreak(r rune) bool {
+	return r == ' ' || r == '\u00a0'
+}
+
+// wrapper collects the lines of Wrap.
+type wrapper struct {
+	cols  int
+	lines []string
+	line  strings.Builder
+	width int
+}
+
+// word adds word and the breaks before it.
+func (w *wrapper) word(sep, word string) {
+	sw, ww := TextWidth(sep), TextWidth(word)
+	switch {
+	case w.width+sw+ww <= w.cols:
+		w.add(sep, sw)
+		w.add(word, ww)
+	case ww <= w.cols:
+		if w.width > 0 {
+			w.flush()
+		}
+		w.add(word, ww)
+	default:
+		// too long for any line: start a fresh one and break inside the word
+		if w.width > 0 {
+			w.flush()
+		}
+		for _, c := range clusters(word) {
+			cw := TextWidth(c)
+			if w.width+cw > w.cols && w.width > 0 {
+				w.flush()
+			}
+			w.add(c, cw)
+		}
+	}
+}
+
+func (w *wrapper) add(s string, width int) {
+	w.line.WriteString(s)
+	w.width += width
+}
+
+func (w *wrapper) flush() {
+	w.lines = append(w.lines, w.line.String())
+	w.line.Reset()
+	w.width = 0
+}
+
+// clusters splits s into characters with their combining marks.
+func clusters(s string) []string {
+	var out []string
+	start := 0
+	for i, r := range s {
+		if i > start && runeWidth(r) > 0 {
+			out = append(out, s[start:i])
+			start = i
+		}
+	}
+	if start < len(s) {
+		out = append(out, s[start:])
+	}
+	return out
+}
+
+// currentFont returns the profile font selected with SetFont.
+func (p *Printer) currentFont() Font {
+	f, _ := p.profile.Font(string(rune('A' + p.font)))
+	return f
+}
+
+// Columns returns how many characters fit on a line in the current font,
+// character size and character spacing, from the printable width of the
+// profile.
+func (p *Printer) Columns() int {
+	p.Lock()
+	defer p.Unlock()
+	return p.columns()
+}
+
+func (p *Printer) columns() int {
+	f := p.currentFont()
+	if f.Width == 0 {
+		return f.Columns / int(max(p.width, 1))
+	}
+	// right-side spacing is magnified with the character
+	cell := (f.Width + int(p.spacing)) * int(max(p.width, 1))
+	return max(p.profile.DotWidth/cell, 1)
+}
+
+// Paragraph prints s word-wrapped to Columns, see Wrap. Each line is aligned
+// as set by SetAlign by padding it with spaces, so alignment does not depend
+// on the printer applying ESC a to wrapped lines. Characters are composed
+// (NFC) first, so an accent sent as a combining mark prints with its letter
+// where the code page has the composed character; marks left over that no
+// code page has are dropped rather than printed as the fallback.
+func (p *Printer) Paragraph(s string) error {
+	return p.ParagraphContext(context.Background(), s)
+}
+
+// ParagraphContext is Paragraph bounded by ctx.
+func (p *Printer) ParagraphContext(ctx context.Context, s string) error {
+	return p.do(ctx, func() error { return p.paragraph(s) })
+}
+
+func (p *Printer) paragraph(s string) error {
+	cols := p.columns()
+	align := p.align
+
+	p.saveState()
+	p.align = cmd.JustifyLeft
+	if err := p.sendAlign(); err != nil {
+		p.restoreState()
+		return err
+	}
+	for _, line := range Wrap(p.dropMarks(norm.NFC.String(s)), cols) {
+		pad := 0
+		switch align {
+		case cmd.JustifyCenter:
+			pad = (cols - TextWidth(line)) / 2
+		case cmd.JustifyRight:
+			pad = cols - TextWidth(line)
+		}
+		if err := p.text(strings.Repeat(" ", max(pad, 0)) + line + "\n"); err != nil {
+			p.restoreState()
+			return err
+		}
+	}
+	return p.restoreState()
+}
+
+// dropMarks removes the combining marks that no code page of the printer
+// has. Text would print each as the fallback in a column of its own, while
+// TextWidth counts them as none.
+func (p *Printer) dropMarks(s string) string {
+	pages, _ := p.textCodePages()
+	return strings.Map(func(r rune) rune {
+		if unicode.In(r, unicode.Mn, unicode.Me) && pickCodePage(pages, []rune{r}) < 0 {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,123 @@
+package printer
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestTextWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"Привет", 6},
+		{"日本語", 6},
+		{"ｶﾀｶﾅ", 4},     // half-width katakana
+		{"Ａ", 2},        // full-width Latin
+		{"e\u0301", 1},  // combining acute
+		{"a\u20dd", 1},  // enclosing circle
+		{"a\u200bb", 2}, // zero-width space
+		{"Цена 100₽", 9},
+	}
+	for _, tt := range tests {
+		if got := TextWidth(tt.s); got != tt.want {
+			t.Errorf("TextWidth(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		cols int
+		want []string
+	}{
+		{"fits", "one two", 10, []string{"one two"}},
+		{"break", "one two three", 8, []string{"one two", "three"}},
+		{"empty", "", 5, []string{""}},
+		{"newlines", "a\n\nb", 5, []string{"a", "", "b"}},
+		{"inner spaces kept", "a  b", 5, []string{"a  b"}},
+		{"spaces at break dropped", "aaa   bbb", 4, []string{"aaa", "bbb"}},
+		{"indent kept", "  ab", 5, []string{"  ab"}},
+		{"cyrillic", "Борщ со сметаной", 8, []string{"Борщ со", "сметаной"}},
+		{"long word on fresh line", "ab verylongword", 6, []string{"ab", "verylo", "ngword"}},
+		{"long word alone", "abcdefgh", 3, []string{"abc", "def", "gh"}},
+		{"wide", "日本語のテキスト", 6, []string{"日本語", "のテキ", "スト"}},
+		{"wide odd columns", "日本語", 5, []string{"日本", "語"}},
+		{"combining marks stay", "e\u0301e\u0301e\u0301", 2, []string{"e\u0301e\u0301", "e\u0301"}},
+		{"combining width", "cafe\u0301 bar", 8, []string{"cafe\u0301 bar"}},
+		{"tab", "a\tb", 5, []string{"a b"}},
+		{"tab break", "aaa\tbbb", 4, []string{"aaa", "bbb"}},
+		{"nbsp break", "aaa\u00a0bbb", 4, []string{"aaa", "bbb"}},
+		{"nbsp kept", "1\u00a0kg", 5, []string{"1\u00a0kg"}},
+		{"zero columns", "ab", 0, []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Wrap(tt.s, tt.cols)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Wrap(%q, %d) = %q, want %q", tt.s, tt.cols, got, tt.want)
+			}
+			for _, line := range got {
+				if TextWidth(line) > max(tt.cols, 1) {
+					t.Errorf("line %q is wider than %d", line, tt.cols)
+				}
+			}
+		})
+	}
+}
+
+func TestColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		font    string
+		width   byte
+		spacing byte
+		want    int
+	}{
+		{"font A", "epson-tm-t20", "A", 1, 0, 48},
+		{"font B", "epson-tm-t20", "B", 1, 0, 64},
+		{"double width", "epson-tm-t20", "A", 2, 0, 24},
+		{"font B double width", "epson-tm-t20", "B", 2, 0, 32},
+		{"spacing", "epson-tm-t20", "A", 1, 4, 36},
+		{"512 dots", "epson-tm-t88", "A", 1, 0, 42},
+		{"58mm", "xprinter-xp-58", "A", 1, 0, 32},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newTestPrinter(t, tt.profile)
+			if err := p.SetFont(tt.font); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.SetFontSize(tt.width, 1); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.SetCharSpacing(tt.spacing); err != nil {
+				t.Fatal(err)
+			}
+			if got := p.Columns(); got != tt.want {
+				t.Errorf("Columns() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParagraphDropsMarks(t *testing.T) {
+	p, w := newTestPrinter(t, "epson-tm-t20")
+	// U+0301 composes with e; U+0308 after q has no composed form and no
+	// code page
+	if err := p.Paragraph("e\u0301q\u0308"); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(w.Bytes(), []byte{'?'}) {
+		t.Errorf("Paragraph printed a fallback: % x", w.Bytes())
+	}
+	if !bytes.Contains(w.Bytes(), []byte("q\n")) {
+		t.Errorf("Paragraph output % x lacks q", w.Bytes())
+	}
+}
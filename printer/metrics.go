@@ -0,0 +1,278 @@
+package printer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jobDurationBuckets are the upper bounds, in seconds, of the job latency
+// histogram.
+var jobDurationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Metrics counts jobs, bytes, errors and status polls of printers and
+// records job latency. Printers report to the Metrics given by WithMetrics,
+// which several printers may share; without it they record nothing. A nil
+// *Metrics records nothing.
+type Metrics struct {
+	mu sync.Mutex
+
+	jobs        counterVec // printer, result
+	bytes       counterVec // printer
+	writeErrors counterVec // printer, kind
+	lpdFailures counterVec // printer, stage
+	statusPolls counterVec // printer, result
+	latency     map[string]*histogram
+}
+
+// NewMetrics returns an empty Metrics, to give to WithMetrics and to
+// expose with Publish, Handler or WritePrometheus.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		jobs:        newCounterVec("printer", "result"),
+		bytes:       newCounterVec("printer"),
+		writeErrors: newCounterVec("printer", "kind"),
+		lpdFailures: newCounterVec("printer", "stage"),
+		statusPolls: newCounterVec("printer", "result"),
+		latency:     make(map[string]*histogram),
+	}
+}
+
+func (m *Metrics) jobDone(printer string, d time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	m.jobs.add(1, printer, result)
+	h, ok := m.latency[printer]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(jobDurationBuckets))}
+		m.latency[printer] = h
+	}
+	h.observe(d.Seconds())
+}
+
+func (m *Metrics) written(printer string, n int, err error) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if n > 0 {
+		m.bytes.add(uint64(n), printer)
+	}
+	if err != nil {
+		m.writeErrors.add(1, printer, errorLabel(err))
+	}
+}
+
+func (m *Metrics) lpdFailed(printer, stage string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lpdFailures.add(1, printer, stage)
+}
+
+func (m *Metrics) statusPolled(printer, result string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.statusPolls.add(1, printer, result)
+}
+
+// errorLabel names the kind of err for metric labels.
+func errorLabel(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, ErrTimeout):
+		return "timeout"
+	case errors.Is(err, ErrOffline):
+		return "offline"
+	case errors.Is(err, ErrPaperOut):
+		return "paper_out"
+	case errors.Is(err, ErrCoverOpen):
+		return "cover_open"
+	case errors.Is(err, ErrCutterJam):
+		return "cutter_jam"
+	case errors.Is(err, ErrNotSupported):
+		return "not_supported"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	}
+	return "other"
+}
+
+// Publish exposes the metrics through expvar under name. Like
+// expvar.Publish it panics if name is already in use, so call it once. A
+// nil *Metrics publishes nothing.
+func (m *Metrics) Publish(name string) {
+	if m == nil {
+		return
+	}
+	expvar.Publish(name, expvar.Func(func() any { return m.snapshot() }))
+}
+
+// snapshot returns the metrics as nested maps for expvar.
+func (m *Metrics) snapshot() map[string]any {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	latency := make(map[string]any, len(m.latency))
+	for printer, h := range m.latency {
+		buckets := make(map[string]uint64, len(h.counts))
+		for i, le := range jobDurationBuckets {
+			buckets[formatFloat(le)] = h.counts[i]
+		}
+		latency[printer] = map[string]any{"count": h.count, "sum": h.sum, "buckets": buckets}
+	}
+	return map[string]any{
+		"jobs":                 m.jobs.snapshot(),
+		"bytes_written":        m.bytes.snapshot(),
+		"write_errors":         m.writeErrors.snapshot(),
+		"lpd_stage_failures":   m.lpdFailures.snapshot(),
+		"status_polls":         m.statusPolls.snapshot(),
+		"job_duration_seconds": latency,
+	}
+}
+
+// Handler serves the metrics in the Prometheus text exposition format, or
+// a 500 if they cannot be written out.
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var b bytes.Buffer
+		if err := m.WritePrometheus(&b); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Write(b.Bytes())
+	})
+}
+
+// WritePrometheus writes the metrics in the Prometheus text format. A nil
+// *Metrics writes nothing.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+	m.jobs.write(&b, "escpos_jobs_total", "Print jobs by result.")
+	m.bytes.write(&b, "escpos_bytes_written_total", "Bytes written to printers.")
+	m.writeErrors.write(&b, "escpos_write_errors_total", "Failed writes by error kind.")
+	m.lpdFailures.write(&b, "escpos_lpd_stage_failures_total", "Failed LPD job stages.")
+	m.statusPolls.write(&b, "escpos_status_polls_total", "Status queries by result.")
+
+	b.WriteString("# HELP escpos_job_duration_seconds Print job latency.\n")
+	b.WriteString("# TYPE escpos_job_duration_seconds histogram\n")
+	printers := make([]string, 0, len(m.latency))
+	for printer := range m.latency {
+		printers = append(printers, printer)
+	}
+	sort.Strings(printers)
+	for _, printer := range printers {
+		h := m.latency[printer]
+		label := `printer="` + escapeLabel(printer) + `"`
+		for i, le := range jobDurationBuckets {
+			fmt.Fprintf(&b, "escpos_job_duration_seconds_bucket{%s,le=\"%s\"} %d\n", label, formatFloat(le), h.counts[i])
+		}
+		fmt.Fprintf(&b, "escpos_job_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", label, h.count)
+		fmt.Fprintf(&b, "escpos_job_duration_seconds_sum{%s} %s\n", label, formatFloat(h.sum))
+		fmt.Fprintf(&b, "escpos_job_duration_seconds_count{%s} %d\n", label, h.count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// counterVec is a set of counters keyed by label values.
+type counterVec struct {
+	labels []string
+	values map[string]uint64 // label values joined by labelSep
+}
+
+const labelSep = "\xff"
+
+func newCounterVec(labels ...string) counterVec {
+	return counterVec{labels: labels, values: make(map[string]uint64)}
+}
+
+func (c *counterVec) add(n uint64, values ...string) {
+	c.values[strings.Join(values, labelSep)] += n
+}
+
+func (c *counterVec) keys() []string {
+	keys := make([]string, 0, len(c.values))
+	for k := range c.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *counterVec) snapshot() map[string]uint64 {
+	out := make(map[string]uint64, len(c.values))
+	for k, v := range c.values {
+		out[strings.ReplaceAll(k, labelSep, "/")] = v
+	}
+	return out
+}
+
+func (c *counterVec) write(b *strings.Builder, name, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	for _, k := range c.keys() {
+		values := strings.Split(k, labelSep)
+		pairs := make([]string, len(c.labels))
+		for i, l := range c.labels {
+			pairs[i] = l + `="` + escapeLabel(values[i]) + `"`
+		}
+		fmt.Fprintf(b, "%s{%s} %d\n", name, strings.Join(pairs, ","), c.values[k])
+	}
+}
+
+// histogram keeps cumulative bucket counts for jobDurationBuckets.
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func (h *histogram) observe(v float64) {
+	for i, le := range jobDurationBuckets {
+		if v <= le {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func formatFloat(f float64) string {
+	return fmt.Sprintf("%g", f)
+}
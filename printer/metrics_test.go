@@ -0,0 +1,90 @@
+package printer
+
+import (
+	"bytes"
+	"expvar"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// closedWriter fails every write like a dropped connection.
+type closedWriter struct{ bufPrinter }
+
+func (closedWriter) Write([]byte) (int, error) { return 0, io.ErrClosedPipe }
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics()
+	p, err := NewPrinter(&bufPrinter{}, WithMetrics(m), WithName(`front "1"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Job(func(j *Job) error {
+		_, err := j.Write([]byte("abc"))
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	dead, err := NewPrinter(&closedWriter{}, WithMetrics(m), WithName("back"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dead.Job(func(j *Job) error {
+		_, err := j.Write([]byte("abc"))
+		return err
+	}); err == nil {
+		t.Fatal("no error from a closed connection")
+	}
+
+	var b bytes.Buffer
+	if err := m.WritePrometheus(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"# TYPE escpos_jobs_total counter",
+		`escpos_jobs_total{printer="back",result="error"} 1`,
+		`escpos_jobs_total{printer="front \"1\"",result="ok"} 1`,
+		`escpos_bytes_written_total{printer="front \"1\""} 3`,
+		`escpos_write_errors_total{printer="back",kind="offline"} 1`,
+		"# TYPE escpos_job_duration_seconds histogram",
+		`escpos_job_duration_seconds_bucket{printer="back",le="+Inf"} 1`,
+		`escpos_job_duration_seconds_count{printer="front \"1\""} 1`,
+	} {
+		if !strings.Contains(b.String(), line+"\n") {
+			t.Errorf("no line %s in\n%s", line, b.String())
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != b.String() {
+		t.Errorf("handler served %d\n%s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("content type %q", ct)
+	}
+
+	m.Publish("escpos_test_metrics")
+	if v := expvar.Get("escpos_test_metrics"); v == nil || !strings.Contains(v.String(), `"bytes_written":{"front \"1\"":3}`) {
+		t.Errorf("expvar %v", v)
+	}
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+	var b bytes.Buffer
+	if err := m.WritePrometheus(&b); err != nil || b.Len() != 0 {
+		t.Errorf("WritePrometheus wrote %q, %v", b.String(), err)
+	}
+	m.Publish("escpos_test_nil_metrics")
+	if expvar.Get("escpos_test_nil_metrics") != nil {
+		t.Error("nil metrics published")
+	}
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+		t.Errorf("handler served %d %q", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,111 @@
+package printer
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Interceptor hooks into every call on a Transport, see WrapTransport.
+// All fields are optional.
+type Interceptor struct {
+	// OnWrite sees the bytes about to be written and returns the bytes to
+	// write instead, e.g. to strip commands a printer does not support.
+	// Returning an error fails the write without touching the connection.
+	OnWrite func(ctx context.Context, b []byte) ([]byte, error)
+
+	// OnRead sees the result of every read.
+	OnRead func(ctx context.Context, b []byte, err error)
+
+	// OnError sees every failed read or write; op is "read" or "write".
+	OnError func(op string, err error)
+
+	// OnClose sees the result of Close.
+	OnClose func(err error)
+}
+
+// WrapTransport returns t with the interceptors applied, the first one
+// outermost: its OnWrite runs first and its OnRead last.
+func WrapTransport(t Transport, interceptors ...Interceptor) Transport {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		t = &interceptedTransport{next: t, in: interceptors[i]}
+	}
+	return t
+}
+
+type interceptedTransport struct {
+	next Transport
+	in   Interceptor
+}
+
+func (t *interceptedTransport) Write(b []byte) (int, error) {
+	return t.WriteContext(context.Background(), b)
+}
+
+func (t *interceptedTransport) Read(b []byte) (int, error) {
+	return t.ReadContext(context.Background(), b)
+}
+
+// WriteContext with OnWrite set writes the rewritten bytes in full and
+// reports len(b), so callers never retry part of a rewritten write.
+func (t *interceptedTransport) WriteContext(ctx context.Context, b []byte) (int, error) {
+	if t.in.OnWrite == nil {
+		n, err := t.next.WriteContext(ctx, b)
+		return n, t.failed("write", err)
+	}
+
+	out, err := t.in.OnWrite(ctx, b)
+	if err != nil {
+		return 0, t.failed("write", err)
+	}
+	for len(out) > 0 {
+		n, err := t.next.WriteContext(ctx, out)
+		if err == nil && n == 0 {
+			err = io.ErrShortWrite
+		}
+		if err != nil {
+			return 0, t.failed("write", err)
+		}
+		out = out[n:]
+	}
+	return len(b), nil
+}
+
+func (t *interceptedTransport) ReadContext(ctx context.Context, b []byte) (int, error) {
+	n, err := t.next.ReadContext(ctx, b)
+	if t.in.OnRead != nil {
+		t.in.OnRead(ctx, b[:n], err)
+	}
+	return n, t.failed("read", err)
+}
+
+func (t *interceptedTransport) Close() error {
+	err := t.next.Close()
+	if t.in.OnClose != nil {
+		t.in.OnClose(err)
+	}
+	return err
+}
+
+func (t *interceptedTransport) failed(op string, err error) error {
+	if err != nil && t.in.OnError != nil {
+		t.in.OnError(op, err)
+	}
+	return err
+}
+
+// JobInfo describes a print job for JobHooks.
+type JobInfo struct {
+	ID       uint64
+	Start    time.Time
+	Duration time.Duration // set in OnJobEnd
+	Bytes    int           // bytes sent, set in OnJobEnd
+}
+
+// JobHooks are called around every Job, JobContext, Print and
+// PrintContext. Both fields are optional.
+type JobHooks struct {
+	OnJobStart func(ctx context.Context, job JobInfo)
+	// OnJobEnd gets the job error, nil if the job was sent.
+	OnJobEnd func(ctx context.Context, job JobInfo, err error)
+}
@@ -0,0 +1,138 @@
+package printer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestInterceptors(t *testing.T) {
+	var order []string
+	var errs []string
+	closed := false
+	outer := Interceptor{
+		OnWrite: func(ctx context.Context, b []byte) ([]byte, error) {
+			order = append(order, "outer")
+			return bytes.ToUpper(b), nil
+		},
+		OnClose: func(err error) { closed = true },
+	}
+	inner := Interceptor{
+		OnWrite: func(ctx context.Context, b []byte) ([]byte, error) {
+			order = append(order, "inner")
+			if bytes.Contains(b, []byte("FAIL")) {
+				return nil, errors.New("rejected")
+			}
+			return append(b, '!'), nil
+		},
+		OnError: func(op string, err error) { errs = append(errs, op+": "+err.Error()) },
+	}
+	w := &bufPrinter{}
+	p, err := NewPrinter(w, WithInterceptor(outer), WithInterceptor(inner))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Text("abc"); err != nil {
+		t.Fatal(err)
+	}
+	if w.String() != "ABC!" {
+		t.Errorf("wrote %q, want ABC!", w.String())
+	}
+	if len(order) != 2 || order[0] != "outer" || order[1] != "inner" {
+		t.Errorf("OnWrite order %v, want outer first", order)
+	}
+
+	if err := p.Text("fail"); err == nil {
+		t.Fatal("rejected write succeeded")
+	}
+	if len(errs) != 1 || errs[0] != "write: rejected" {
+		t.Errorf("OnError saw %v", errs)
+	}
+	if w.String() != "ABC!" {
+		t.Errorf("rejected write reached the connection: %q", w.String())
+	}
+
+	if err := p.CloseConnection(); err != nil {
+		t.Fatal(err)
+	}
+	if !closed {
+		t.Error("OnClose not called")
+	}
+}
+
+func TestInterceptorOnRead(t *testing.T) {
+	var seen []byte
+	var readErr error
+	in := Interceptor{OnRead: func(ctx context.Context, b []byte, err error) {
+		seen = append(seen, b...)
+		readErr = err
+	}}
+	tr := WrapTransport(&RawTransport{conn: nopCloser{bytes.NewBufferString("\x16")}}, in)
+	b := make([]byte, 4)
+	if _, err := tr.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(seen, []byte{0x16}) {
+		t.Errorf("OnRead saw % x", seen)
+	}
+	if _, err := tr.Read(b); err != io.EOF || readErr != io.EOF {
+		t.Errorf("read error %v, OnRead saw %v, want EOF", err, readErr)
+	}
+}
+
+func TestJobHooks(t *testing.T) {
+	var starts []JobInfo
+	var ends []JobInfo
+	var endErrs []error
+	hooks := JobHooks{
+		OnJobStart: func(ctx context.Context, job JobInfo) { starts = append(starts, job) },
+		OnJobEnd: func(ctx context.Context, job JobInfo, err error) {
+			ends = append(ends, job)
+			endErrs = append(endErrs, err)
+		},
+	}
+	p, _ := newTestPrinter(t, "epson-tm-t20", WithJobHooks(hooks))
+
+	if err := p.Job(func(j *Job) error {
+		_, err := j.Write([]byte("hello\n"))
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Print(NewDocument().Text("abc")); err != nil {
+		t.Fatal(err)
+	}
+	failed := errors.New("no receipt")
+	if err := p.Job(func(j *Job) error { return failed }); !errors.Is(err, failed) {
+		t.Fatalf("job error %v", err)
+	}
+
+	if len(starts) != 3 || len(ends) != 3 {
+		t.Fatalf("%d starts, %d ends, want 3 each", len(starts), len(ends))
+	}
+	for i, want := range []struct {
+		id    uint64
+		bytes int
+		err   error
+	}{
+		{1, 6, nil},
+		{2, 3, nil},
+		{3, 0, failed},
+	} {
+		if starts[i].ID != want.id || ends[i].ID != want.id {
+			t.Errorf("job %d: IDs %d and %d, want %d", i, starts[i].ID, ends[i].ID, want.id)
+		}
+		if ends[i].Bytes != want.bytes {
+			t.Errorf("job %d: %d bytes, want %d", i, ends[i].Bytes, want.bytes)
+		}
+		if !errors.Is(endErrs[i], want.err) {
+			t.Errorf("job %d: OnJobEnd error %v, want %v", i, endErrs[i], want.err)
+		}
+		if ends[i].Start != starts[i].Start || ends[i].Duration < 0 {
+			t.Errorf("job %d: start %v / %v, duration %v", i, starts[i].Start, ends[i].Start, ends[i].Duration)
+		}
+	}
+}
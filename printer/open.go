@@ -0,0 +1,251 @@
+package printer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/gousb"
+	"go.bug.st/serial"
+)
+
+// Open connects to the printer described by dsn, one of
+//
+//	tcp://10.0.0.5:9100
+//	lpd://host:515/queue?user=pos
+//	serial:///dev/ttyUSB0?baud=115200
+//	usb://04b8:0202?serial=X123
+//	file:///dev/usb/lp0
+//
+// Every scheme also takes profile, dialect, codepage, kanji and timeout
+// (write timeout, e.g. "5s") query parameters. opts are applied after those, so
+// they win.
+// ctx bounds connecting only.
+func Open(ctx context.Context, dsn string, opts ...Option) (*Printer, error) {
+	u, err := parseDSN(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid printer DSN %q: %w", dsn, err)
+	}
+	q := u.Query()
+
+	common, err := queryOptions(q)
+	if err != nil {
+		return nil, fmt.Errorf("invalid printer DSN %q: %w", dsn, err)
+	}
+	opts = append(common, opts...)
+
+	switch u.Scheme {
+	case "tcp":
+		addr, err := dialAddr(u, "9100")
+		if err != nil {
+			return nil, fmt.Errorf("invalid printer DSN %q: %w", dsn, err)
+		}
+		conn, err := dial(ctx, addr)
+		if err != nil {
+			return nil, err
+		}
+		return closeOnError(conn)(NewPrinter(conn, append([]Option{WithTransport(TransportRaw)}, opts...)...))
+
+	case "lpd":
+		addr, err := dialAddr(u, "515")
+		if err != nil {
+			return nil, fmt.Errorf("invalid printer DSN %q: %w", dsn, err)
+		}
+		conn, err := dial(ctx, addr)
+		if err != nil {
+			return nil, err
+		}
+		lpd := []Option{WithTransport(TransportLPD)}
+		if queue := strings.Trim(u.Path, "/"); queue != "" {
+			lpd = append(lpd, WithLPDQueue(queue))
+		}
+		if user := q.Get("user"); user != "" {
+			lpd = append(lpd, WithLPDUser(user))
+		}
+		return closeOnError(conn)(NewPrinter(conn, append(lpd, opts...)...))
+
+	case "serial":
+		name := u.Path
+		if name == "" {
+			name = u.Host // serial://COM3
+		}
+		mode, err := serialMode(q)
+		if err != nil {
+			return nil, fmt.Errorf("invalid printer DSN %q: %w", dsn, err)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return newSerialPrinter(name, mode, opts...)
+
+	case "usb":
+		vid, pid, err := usbIDs(u.Host)
+		if err != nil {
+			return nil, fmt.Errorf("invalid printer DSN %q: %w", dsn, err)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return newUSBPrinter(vid, pid, q.Get("serial"), opts...)
+
+	case "file":
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		f, err := os.OpenFile(u.Path, os.O_RDWR, 0)
+		if err != nil {
+			// many printer device files are write-only
+			f, err = os.OpenFile(u.Path, os.O_WRONLY, 0)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return closeOnError(f)(NewPrinter(f, opts...))
+	}
+	return nil, fmt.Errorf("unsupported printer DSN scheme: %q", u.Scheme)
+}
+
+func queryOptions(q url.Values) ([]Option, error) {
+	var opts []Option
+	if name := q.Get("profile"); name != "" {
+		prof, err := LookupProfile(name)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithProfile(prof))
+	}
+	if name := q.Get("dialect"); name != "" {
+		d, err := LookupDialect(name)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithDialect(d))
+	}
+	if cp := q.Get("codepage"); cp != "" {
+		opts = append(opts, WithCodePage(cp))
+	}
+	if k := q.Get("kanji"); k != "" {
+		opts = append(opts, WithKanji(k))
+	}
+	if t := q.Get("timeout"); t != "" {
+		d, err := time.ParseDuration(t)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout: %w", err)
+		}
+		opts = append(opts, WithWriteTimeout(d))
+	}
+	return opts, nil
+}
+
+// parseDSN parses dsn as a URL. url.Parse takes the product id of
+// usb://04b8:0202 for a port and rejects it, so the vendor:product pair is
+// cut from the raw string and kept as the Host.
+func parseDSN(dsn string) (*url.URL, error) {
+	if len(dsn) >= len("usb://") && strings.EqualFold(dsn[:len("usb://")], "usb://") {
+		ids, query, _ := strings.Cut(dsn[len("usb://"):], "?")
+		return &url.URL{Scheme: "usb", Host: strings.TrimSuffix(ids, "/"), RawQuery: query}, nil
+	}
+	return url.Parse(dsn)
+}
+
+// dialAddr returns the host:port of a tcp or lpd DSN, with defaultPort if
+// it has none. IPv6 hosts are written in brackets, tcp://[::1]:9100.
+func dialAddr(u *url.URL, defaultPort string) (string, error) {
+	host, port := u.Hostname(), u.Port()
+	if host == "" {
+		return "", fmt.Errorf("missing host")
+	}
+	if port == "" {
+		port = defaultPort
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+func dial(ctx context.Context, addr string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, "tcp", addr)
+}
+
+// closeOnError closes c if NewPrinter fails, so Open never leaks it.
+func closeOnError(c interface{ Close() error }) func(*Printer, error) (*Printer, error) {
+	return func(p *Printer, err error) (*Printer, error) {
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+		return p, nil
+	}
+}
+
+// serialMode reads baud (default 9600), databits, parity (none, odd, even),
+// stopbits (1, 2) and flow (none). go.bug.st/serial has no hardware
+// handshake setting, so flow=rtscts fails with ErrNotSupported; enable the
+// handshake on the port instead (e.g. stty crtscts) and leave flow out.
+func serialMode(q url.Values) (*serial.Mode, error) {
+	mode := &serial.Mode{
+		BaudRate: 9600,
+		DataBits: 8,
+		Parity:   serial.NoParity,
+		StopBits: serial.OneStopBit,
+	}
+	if v := q.Get("baud"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid baud: %s", v)
+		}
+		mode.BaudRate = n
+	}
+	if v := q.Get("databits"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 5 || n > 8 {
+			return nil, fmt.Errorf("invalid databits: %s", v)
+		}
+		mode.DataBits = n
+	}
+	switch v := q.Get("parity"); v {
+	case "", "none":
+	case "odd":
+		mode.Parity = serial.OddParity
+	case "even":
+		mode.Parity = serial.EvenParity
+	default:
+		return nil, fmt.Errorf("invalid parity: %s", v)
+	}
+	switch v := q.Get("stopbits"); v {
+	case "", "1":
+	case "2":
+		mode.StopBits = serial.TwoStopBits
+	default:
+		return nil, fmt.Errorf("invalid stopbits: %s", v)
+	}
+	switch v := q.Get("flow"); v {
+	case "", "none":
+	case "rtscts":
+		return nil, fmt.Errorf("%w: flow=rtscts", ErrNotSupported)
+	default:
+		return nil, fmt.Errorf("invalid flow: %s", v)
+	}
+	return mode, nil
+}
+
+// usbIDs parses "04b8:0202" into vendor and product IDs.
+func usbIDs(s string) (gousb.ID, gousb.ID, error) {
+	vs, ps, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid USB id %q, want vendor:product", s)
+	}
+	vid, err := strconv.ParseUint(vs, 16, 16)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid USB vendor id %q", vs)
+	}
+	pid, err := strconv.ParseUint(ps, 16, 16)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid USB product id %q", ps)
+	}
+	return gousb.ID(vid), gousb.ID(pid), nil
+}
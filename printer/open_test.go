@@ -0,0 +1,139 @@
+package printer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/gousb"
+)
+
+func TestDialAddr(t *testing.T) {
+	tests := []struct {
+		dsn, port, want, err string
+	}{
+		{"tcp://10.0.0.5", "9100", "10.0.0.5:9100", ""},
+		{"tcp://10.0.0.5:9101", "9100", "10.0.0.5:9101", ""},
+		{"tcp://[::1]", "9100", "[::1]:9100", ""},
+		{"tcp://[::1]:9101", "9100", "[::1]:9101", ""},
+		{"tcp://printer.local?profile=epson-tm-t20", "9100", "printer.local:9100", ""},
+		{"lpd://host/queue", "515", "host:515", ""},
+		{"lpd://[fe80::1]:1515/queue?user=pos", "515", "[fe80::1]:1515", ""},
+		{"tcp://", "9100", "", "missing host"},
+		{"tcp://:9100", "9100", "", "missing host"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dsn, func(t *testing.T) {
+			u, err := parseDSN(tt.dsn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := dialAddr(u, tt.port)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("address %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDSNUSB(t *testing.T) {
+	tests := []struct {
+		dsn      string
+		vid, pid gousb.ID
+		serial   string
+		err      string
+	}{
+		{"usb://04b8:0202", 0x04b8, 0x0202, "", ""},
+		{"usb://04b8:0e15", 0x04b8, 0x0e15, "", ""},
+		{"usb://04B8:0E15/?serial=X123", 0x04b8, 0x0e15, "X123", ""},
+		{"USB://0519:0003?serial=A&profile=star-tsp100", 0x0519, 0x0003, "A", ""},
+		{"usb://04b8", 0, 0, "", "want vendor:product"},
+		{"usb://04b8:xyz", 0, 0, "", "invalid USB product id"},
+		{"usb://10000:0202", 0, 0, "", "invalid USB vendor id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dsn, func(t *testing.T) {
+			u, err := parseDSN(tt.dsn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if u.Scheme != "usb" {
+				t.Fatalf("scheme %q, want usb", u.Scheme)
+			}
+			vid, pid, err := usbIDs(u.Host)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if vid != tt.vid || pid != tt.pid {
+				t.Errorf("ids %v:%v, want %v:%v", vid, pid, tt.vid, tt.pid)
+			}
+			if got := u.Query().Get("serial"); got != tt.serial {
+				t.Errorf("serial %q, want %q", got, tt.serial)
+			}
+		})
+	}
+}
+
+func TestParseDSN(t *testing.T) {
+	tests := []struct {
+		dsn, scheme, host, path, query string
+	}{
+		{"serial:///dev/ttyUSB0?baud=115200", "serial", "", "/dev/ttyUSB0", "baud=115200"},
+		{"serial://COM3", "serial", "COM3", "", ""},
+		{"file:///dev/usb/lp0", "file", "", "/dev/usb/lp0", ""},
+		{"lpd://host:515/queue?user=pos", "lpd", "host:515", "/queue", "user=pos"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dsn, func(t *testing.T) {
+			u, err := parseDSN(tt.dsn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if u.Scheme != tt.scheme || u.Host != tt.host || u.Path != tt.path || u.RawQuery != tt.query {
+				t.Errorf("got %s %q %q %q, want %s %q %q %q",
+					u.Scheme, u.Host, u.Path, u.RawQuery, tt.scheme, tt.host, tt.path, tt.query)
+			}
+		})
+	}
+}
+
+func TestOpenInvalidDSN(t *testing.T) {
+	tests := []struct {
+		dsn string
+		err error
+	}{
+		{"serial:///dev/ttyS0?flow=rtscts", ErrNotSupported},
+	}
+	for _, tt := range tests {
+		if _, err := Open(t.Context(), tt.dsn); !errors.Is(err, tt.err) {
+			t.Errorf("%s: error %v, want %v", tt.dsn, err, tt.err)
+		}
+	}
+	for _, dsn := range []string{
+		"tcp://",
+		"usb://04b8",
+		"serial:///dev/ttyS0?baud=fast",
+		"tcp://host?timeout=soon",
+		"tcp://host?profile=nope",
+		"gopher://host",
+	} {
+		if _, err := Open(t.Context(), dsn); err == nil {
+			t.Errorf("%s: no error", dsn)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package printer
+
+import (
+	"image"
+	"log/slog"
+	"time"
+
+	"github.com/AlexStarov/escpos-GoLang-lib/codepage"
+)
+
+// TransportKind selects how NewPrinter talks to the connection.
+type TransportKind string
+
+const (
+	// TransportRaw passes bytes straight through, e.g. port 9100 or a device file.
+	TransportRaw TransportKind = "raw"
+	// TransportLPD buffers the job and sends it over the LPD protocol on close.
+	TransportLPD TransportKind = "lpd"
+)
+
+// options collects the settings given to NewPrinter.
+type options struct {
+	transport TransportKind
+	lpdQueue  string
+	lpdUser   string
+
+	profile  *Profile
+	dialect  Dialect
+	codePage string
+	kanji    string
+	fallback func(r rune) string
+
+	codeTables []codeTable
+	userChars  map[rune]image.Image
+
+	name         string
+	logger       *slog.Logger
+	writeTimeout time.Duration
+
+	interceptors []Interceptor
+	jobHooks     []JobHooks
+
+	metrics *Metrics
+}
+
+// Option configures a Printer in NewPrinter and the constructors built on it.
+type Option func(*options)
+
+func defaultOptions() options {
+	return options{
+		transport: TransportRaw,
+		lpdQueue:  "lp",
+	}
+}
+
+// WithTransport selects the transport; the default is TransportRaw.
+// TransportLPD needs a net.Conn.
+func WithTransport(kind TransportKind) Option {
+	return func(o *options) { o.transport = kind }
+}
+
+// WithLPDQueue sets the LPD queue name, "lp" by default.
+func WithLPDQueue(queue string) Option {
+	return func(o *options) { o.lpdQueue = queue }
+}
+
+// WithLPDUser sets the user name sent in the LPD control file; by default
+// it is taken from $USER.
+func WithLPDUser(user string) Option {
+	return func(o *options) { o.lpdUser = user }
+}
+
+// WithProfile sets the capability profile, DefaultProfile by default.
+func WithProfile(profile *Profile) Option {
+	return func(o *options) { o.profile = profile }
+}
+
+// WithDialect sets the command set, by default the one named by the
+// profile.
+func WithDialect(d Dialect) Option {
+	return func(o *options) { o.dialect = d }
+}
+
+// WithCodePage sets the code page selected by Init. The name must be one of
+// the code pages of the profile.
+func WithCodePage(name string) Option {
+	return func(o *options) { o.codePage = name }
+}
+
+// codeTable is a table of WithCodeTable and its ESC t number.
+type codeTable struct {
+	table *codepage.Table
+	id    byte
+}
+
+// WithCodeTable adds a code page the printer firmware has as ESC t id, for
+// tables not built into the codepage package or found at a non-standard
+// number. The profile is copied, not changed.
+func WithCodeTable(t *codepage.Table, id byte) Option {
+	return func(o *options) { o.codeTables = append(o.codeTables, codeTable{t, id}) }
+}
+
+// WithKanji sets the double-byte encoding of the printer's Kanji / CJK font,
+// one of the Kanji* names, for printers whose profile does not have it.
+func WithKanji(name string) Option {
+	return func(o *options) { o.kanji = name }
+}
+
+// WithUserChar makes Text print r, when no code page has it, as img
+// downloaded into a user-defined character slot. The image is scaled down to
+// fit the character cell; dark pixels print. It replaces the built-in glyph
+// of r, if any.
+func WithUserChar(r rune, img image.Image) Option {
+	return func(o *options) {
+		if o.userChars == nil {
+			o.userChars = map[rune]image.Image{}
+		}
+		o.userChars[r] = img
+	}
+}
+
+// WithTextFallback sets what Text prints for a character that none of the
+// printer's code pages has, e.g. a transliteration; the default is "?".
+// The result is encoded in the current code page.
+func WithTextFallback(fn func(r rune) string) Option {
+	return func(o *options) { o.fallback = fn }
+}
+
+// WithLogger sets the logger for the printer and its transport. Without it
+// the printer logs nothing.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) { o.logger = logger }
+}
+
+// WithName names the printer; the name is added to every log record and
+// metric as the "printer" label.
+func WithName(name string) Option {
+	return func(o *options) { o.name = name }
+}
+
+// WithInterceptor wraps the transport with i, see WrapTransport. Repeated
+// options nest in the order given.
+func WithInterceptor(i Interceptor) Option {
+	return func(o *options) { o.interceptors = append(o.interceptors, i) }
+}
+
+// WithJobHooks adds hooks called around every print job.
+func WithJobHooks(h JobHooks) Option {
+	return func(o *options) { o.jobHooks = append(o.jobHooks, h) }
+}
+
+// WithMetrics sets where the printer reports metrics; by default it
+// reports none.
+func WithMetrics(m *Metrics) Option {
+	return func(o *options) { o.metrics = m }
+}
+
+// discardLogger is the default logger.
+var discardLogger = slog.New(slog.DiscardHandler)
+
+func (o *options) log() *slog.Logger {
+	logger := o.logger
+	if logger == nil {
+		logger = discardLogger
+	}
+	if o.name != "" {
+		logger = logger.With("printer", o.name)
+	}
+	return logger
+}
+
+// loggerFrom resolves the logger configured by opts, for constructors that
+// log before NewPrinter runs.
+func loggerFrom(opts []Option) *slog.Logger {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o.log()
+}
+
+// WithWriteTimeout bounds every write that does not already carry a
+// context deadline.
+func WithWriteTimeout(d time.Duration) Option {
+	return func(o *options) { o.writeTimeout = d }
+}
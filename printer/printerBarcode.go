@@ -0,0 +1,80 @@
+package printer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/AlexStarov/escpos-GoLang-lib/cmd"
+)
+
+// barcodeSymbologies maps symbology names to the cmd.Barcode* systems.
+var barcodeSymbologies = map[string]byte{
+	"upc-a":   cmd.BarcodeUPCA,
+	"upc-e":   cmd.BarcodeUPCE,
+	"ean13":   cmd.BarcodeEAN13,
+	"ean8":    cmd.BarcodeEAN8,
+	"code39":  cmd.BarcodeCODE39,
+	"itf":     cmd.BarcodeITF,
+	"codabar": cmd.BarcodeCODABAR,
+	"code93":  cmd.BarcodeCODE93,
+	"code128": cmd.BarcodeCODE128,
+}
+
+// barcodeSymbology returns the name of GS k system m, function A or B.
+func barcodeSymbology(m byte) string {
+	if m <= 6 {
+		m += cmd.BarcodeUPCA
+	}
+	for name, sys := range barcodeSymbologies {
+		if sys == m {
+			return name
+		}
+	}
+	return ""
+}
+
+// Barcode prints data as a 1D barcode. It fails with ErrNotSupported for a
+// symbology the profile does not list. On ESC/POS, CODE128 data without an
+// explicit code set prefix is sent as code set B.
+func (p *Printer) Barcode(symbology, data string) error {
+	return p.BarcodeContext(context.Background(), symbology, data)
+}
+
+// BarcodeContext is Barcode bounded by ctx.
+func (p *Printer) BarcodeContext(ctx context.Context, symbology, data string) error {
+	return p.do(ctx, func() error { return p.barcode(symbology, data) })
+}
+
+func (p *Printer) barcode(symbology, data string) error {
+	m, ok := barcodeSymbologies[strings.ToLower(symbology)]
+	if !ok {
+		return fmt.Errorf("unknown barcode symbology: %s", symbology)
+	}
+	if !p.profile.hasBarcode(symbology) {
+		return fmt.Errorf("%w: %s does not print %s barcodes", ErrNotSupported, p.profile.Name, symbology)
+	}
+	return p.writeCmd(p.dialect.Barcode(m, []byte(data)))
+}
+
+// QRCode prints data as a model 2 QR code. size is the module
+// size in dots (1–16), level the error correction level: L, M, Q or H.
+func (p *Printer) QRCode(data string, size byte, level string) error {
+	return p.QRCodeContext(context.Background(), data, size, level)
+}
+
+// QRCodeContext is QRCode bounded by ctx.
+func (p *Printer) QRCodeContext(ctx context.Context, data string, size byte, level string) error {
+	return p.do(ctx, func() error { return p.qrCode(data, size, level) })
+}
+
+func (p *Printer) qrCode(data string, size byte, level string) error {
+	if !p.profile.QRCode {
+		return fmt.Errorf("%w: %s does not print QR codes", ErrNotSupported, p.profile.Name)
+	}
+	ec := strings.Index("LMQH", strings.ToUpper(level))
+	if len(level) != 1 || ec < 0 {
+		return fmt.Errorf("invalid QR error correction level: %s", level)
+	}
+	return p.writeCmd(p.dialect.QRCode([]byte(data), size, byte(cmd.QRLevelL+ec)))
+}
@@ -0,0 +1,67 @@
+package printer
+
+import (
+	"errors"
+	"testing"
+)
+
+// newBarcodePrinter returns a printer on the default profile limited to
+// the given symbologies, all of them if none.
+func newBarcodePrinter(t *testing.T, barcodes ...string) (*Printer, *bufPrinter) {
+	t.Helper()
+	prof := DefaultProfile()
+	prof.Barcodes = barcodes
+	w := &bufPrinter{}
+	p, err := NewPrinter(w, WithProfile(prof))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p, w
+}
+
+func TestBarcodeProfile(t *testing.T) {
+	limited := []string{"upc-a", "ean13", "ean8", "code39"}
+	tests := []struct {
+		barcodes  []string
+		symbology string
+		err       error
+	}{
+		{nil, "code128", nil},
+		{nil, "code93", nil},
+		{limited, "ean13", nil},
+		{limited, "CODE39", nil},
+		{limited, "code128", ErrNotSupported},
+		{limited, "code93", ErrNotSupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.symbology, func(t *testing.T) {
+			p, w := newBarcodePrinter(t, tt.barcodes...)
+			err := p.Barcode(tt.symbology, "12345670")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error %v, want %v", err, tt.err)
+			}
+			if err != nil && w.Len() != 0 {
+				t.Errorf("% x sent with the error", w.Bytes())
+			}
+		})
+	}
+}
+
+func TestCompileBarcodeDefaultProfile(t *testing.T) {
+	for _, symbology := range []string{"code128", "code93", "ean13"} {
+		if _, err := NewDocument().Barcode(symbology, "4006381333931").Compile(); err != nil {
+			t.Errorf("%s: %v", symbology, err)
+		}
+	}
+}
+
+func TestWriteRawBarcodeProfile(t *testing.T) {
+	p, _ := newBarcodePrinter(t, "upc-a")
+	// GS k function A UPC-A, then function B CODE128
+	if err := p.WriteRaw([]byte("\x1dk\x0001234567890\x00")); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.WriteRaw([]byte("\x1dkI\x04{BA1")); !errors.Is(err, ErrNotSupported) {
+		t.Fatalf("error %v, want ErrNotSupported", err)
+	}
+}
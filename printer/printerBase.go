@@ -1,294 +1,869 @@
 package printer
 
 import (
+	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"image"
 	"io"
-	"log"
+	"log/slog"
 	"net"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
-	logInternal "github.com/AlexStarov/escpos-GoLang-lib/log"
+	"golang.org/x/text/encoding"
+
+	"github.com/AlexStarov/escpos-GoLang-lib/cmd"
+	"github.com/AlexStarov/escpos-GoLang-lib/codepage"
 )
 
 const gs8lMaxY = 831
 
+// statusTimeout bounds ReadStatusContext when ctx carries no deadline.
+const statusTimeout = 1 * time.Second
+
 type Printer struct {
 	t Transport
 
-	// font metrics
-	width, height byte
+	// capabilities of the printer model
+	profile *Profile
+	// command set the high-level calls are translated to
+	dialect Dialect
+
+	// ESC t number selected by Init and used by Text, if hasCodePage
+	fixedCodePage byte
+	hasCodePage   bool
+	// replacement for characters in no code page, see WithTextFallback
+	fallback func(r rune) string
+	// tables of WithCodeTable, before those of the codepage package
+	codeTables []*codepage.Table
+	// encoding of the Kanji font, nil if none
+	kanji encoding.Encoding
+	// glyphs of WithUserChar, before the built-in ones
+	userGlyphs map[rune]image.Image
+	// user-defined character slots the printer is known to have, by rune
+	userSlots map[rune]userSlot
+
+	name         string
+	logger       *slog.Logger
+	metrics      *Metrics
+	writeTimeout time.Duration
+
+	// number of jobs started, used as the job ID
+	jobs     uint64
+	jobHooks []JobHooks
+
+	// formatting asked for by the caller
+	style
+	// formatting the printer is known to have, unknownStyle until synced
+	device style
+	// stack of SaveState
+	saved []style
+
+	// err is the first transport failure; once set every command returns it
+	err error
+
+	// bounds the writes of the call in progress, see do
+	ctx context.Context
+
+	// guards the transport and the formatting state: held for one command,
+	// a status query or a Job. Exported methods take it and call unexported
+	// ones, which never lock.
+	sync.Mutex
+}
 
-	// state toggles ESC[char]
-	underline  byte
-	emphasize  byte
-	upsidedown byte
-	rotate     byte
+// NewPrinter creates a Printer on top of w, configured by opts. Network
+// connections use TransportRaw unless WithTransport says otherwise. With
+// TransportRaw, a w that is already a Transport, such as a BufferTransport,
+// is used as it is.
+func NewPrinter(w io.ReadWriter, opts ...Option) (*Printer, error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.profile == nil {
+		o.profile = DefaultProfile()
+	}
+	logger := o.log()
 
-	// state toggles GS[char]
-	reverse, smooth byte
+	var transport Transport
 
-	sync.Mutex
+	switch o.transport {
+	case TransportLPD:
+		conn, ok := w.(net.Conn)
+		if !ok {
+			return nil, fmt.Errorf("LPD transport needs a net.Conn, got %T", w)
+		}
+		lpd := NewLPDTransport(conn, o.lpdQueue)
+		lpd.user = o.lpdUser
+		lpd.logger = logger
+		lpd.metrics, lpd.name = o.metrics, o.name
+		transport = lpd
+	case TransportRaw:
+		if t, ok := w.(Transport); ok {
+			transport = t
+		} else if rc, ok := w.(io.ReadWriteCloser); ok {
+			transport = &RawTransport{conn: rc}
+		} else {
+			// Любой io.ReadWriter (например, bytes.Buffer) — оборачиваем в nopCloser и RAW
+			transport = &RawTransport{conn: nopCloser{w}}
+		}
+	default:
+		return nil, fmt.Errorf("unknown transport: %s", o.transport)
+	}
+	transport = WrapTransport(transport, o.interceptors...)
+
+	dialect := o.dialect
+	if dialect == nil {
+		var err error
+		if dialect, err = LookupDialect(o.profile.Dialect); err != nil {
+			return nil, err
+		}
+	}
+
+	var codeTables []*codepage.Table
+	if len(o.codeTables) > 0 {
+		o.profile = o.profile.clone()
+		for _, ct := range o.codeTables {
+			o.profile.AddCodePage(ct.table.Name(), ct.id)
+			codeTables = append(codeTables, ct.table)
+		}
+	}
+
+	if o.kanji != "" {
+		o.profile = o.profile.clone()
+		o.profile.Kanji = o.kanji
+	}
+	kanji, err := lookupKanji(o.profile.Kanji)
+	if err != nil {
+		return nil, err
+	}
+
+	var codePage byte
+	if o.codePage != "" {
+		id, ok := o.profile.CodePage(o.codePage)
+		if !ok {
+			return nil, fmt.Errorf("%s has no code page %s", o.profile.Name, o.codePage)
+		}
+		codePage = id
+	}
+
+	p := &Printer{
+		t:             transport,
+		profile:       o.profile,
+		dialect:       dialect,
+		fixedCodePage: codePage,
+		hasCodePage:   o.codePage != "",
+		fallback:      o.fallback,
+		codeTables:    codeTables,
+		kanji:         kanji,
+		userGlyphs:    o.userChars,
+		logger:        logger,
+		writeTimeout:  o.writeTimeout,
+		jobHooks:      o.jobHooks,
+		name:          o.name,
+		metrics:       o.metrics,
+		style:         defaultStyle,
+		device:        unknownStyle,
+	}
+	if p.hasCodePage {
+		p.style.codePage = int(codePage)
+	}
+	return p, nil
 }
 
-func NewPrinter(w io.ReadWriter) (*Printer, error) {
-    var transport Transport
-
-    // Если нам дали сетевое соединение — проверим порт
-    if conn, ok := w.(net.Conn); ok {
-        addr := conn.RemoteAddr().String()
-        // LPD соединение (порт 515): используем буферизованный LPDTransport
-        if strings.HasSuffix(addr, ":515") {
-            transport = NewLPDTransport(conn, "lp")
-        } else {
-            // Любой другой порт — просто raw passthrough
-            transport = &RawTransport{conn: conn}
-        }
-    } else if rc, ok := w.(io.ReadWriteCloser); ok {
-        // Не сетевой conn — трактуем как RAW
-        transport = &RawTransport{conn: rc}
-    } else {
-        // Любой io.ReadWriter (например, bytes.Buffer) — оборачиваем в nopCloser и RAW
-        transport = &RawTransport{conn: nopCloser{w}}
-    }
-
-    return &Printer{
-        t:      transport,
-        width:  1,
-        height: 1,
-    }, nil
-}
-
-func (p *Printer) ReadStatus() bool {
-	statusOnline := []byte{0x10, 0x04, 0x01}
-	p.t.Write(statusOnline)
-	time.Sleep(1 * time.Second)
-	buf := make([]byte, 1)
-	p.t.Read(buf)
+// Profile returns the capability profile of the printer.
+func (p *Printer) Profile() *Profile {
+	return p.profile
+}
+
+// Dialect returns the command set the printer is driven with.
+func (p *Printer) Dialect() Dialect {
+	return p.dialect
+}
+
+// ReadStatus sends DLE EOT 1 and reports whether the printer is online.
+func (p *Printer) ReadStatus() (bool, error) {
+	return p.ReadStatusContext(context.Background())
+}
+
+// ReadStatusContext is ReadStatus bounded by ctx. Without a ctx deadline the
+// reply is awaited for statusTimeout.
+func (p *Printer) ReadStatusContext(ctx context.Context) (bool, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	status, err := p.queryStatus(ctx, 1)
+	if err != nil {
+		p.metrics.statusPolled(p.name, "error")
+		return false, err
+	}
 	maskOnline := byte(uint(8))
-	if len(buf) == 0 {
-		return false
+	online := (status & maskOnline) == 0
+	if online {
+		p.metrics.statusPolled(p.name, "online")
+	} else {
+		p.metrics.statusPolled(p.name, "offline")
 	}
-	return (buf[0] & maskOnline) == 0
+	return online, nil
 }
 
-func (p *Printer) Reset() {
-	p.width = 1
-	p.height = 1
+// Check queries the printer with DLE EOT 1–4 and returns nil if it is ready,
+// or ErrCutterJam, ErrCoverOpen, ErrPaperOut or ErrOffline, most specific
+// first.
+func (p *Printer) Check(ctx context.Context) error {
+	p.Lock()
+	defer p.Unlock()
+
+	err := p.check(ctx)
+	p.metrics.statusPolled(p.name, errorLabel(err))
+	return err
+}
+
+func (p *Printer) check(ctx context.Context) error {
+	var status [5]byte
+	for n := byte(1); n <= 4; n++ {
+		b, err := p.queryStatus(ctx, n)
+		if err != nil {
+			return err
+		}
+		status[n] = b
+	}
+
+	switch {
+	case status[3]&0x08 != 0: // error status: autocutter error
+		return ErrCutterJam
+	case status[2]&0x04 != 0: // offline cause: cover open
+		return ErrCoverOpen
+	case status[4]&0x60 != 0, status[2]&0x20 != 0: // roll sensor: paper end
+		return ErrPaperOut
+	case status[1]&0x08 != 0: // printer status: offline
+		return ErrOffline
+	}
+	return nil
+}
+
+// queryStatus sends DLE EOT n and returns the status byte. Without a ctx
+// deadline the reply is awaited for statusTimeout. The caller holds the lock.
+func (p *Printer) queryStatus(ctx context.Context, n byte) (byte, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, statusTimeout)
+		defer cancel()
+	}
+
+	b, err := cmd.TransmitStatus(n)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := p.send(ctx, b); err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 1)
+	for {
+		r, err := p.t.ReadContext(ctx, buf)
+		if err != nil {
+			return 0, err
+		}
+		// serial ports return 0 bytes on their own read timeout
+		if r > 0 {
+			return buf[0], nil
+		}
+	}
+}
 
-	p.underline = 0
-	p.emphasize = 0
-	p.upsidedown = 0
-	p.rotate = 0
+// Reset sets the character formatting back to the defaults; alignment and
+// code page are kept. Nothing is sent until the next Send or Set call.
+func (p *Printer) Reset() {
+	p.Lock()
+	defer p.Unlock()
+	p.reset()
+}
 
-	p.reverse = 0
-	p.smooth = 0
+func (p *Printer) reset() {
+	align, codePage := p.align, p.codePage
+	p.style = defaultStyle
+	p.align, p.codePage = align, codePage
 }
 
 func (p *Printer) CloseConnection() error {
+	p.Lock()
+	defer p.Unlock()
 	return p.t.Close()
 }
 
+// Err returns the first transport error seen by the printer, or nil.
+// Cancelled and timed out writes are not transport errors.
+func (p *Printer) Err() error {
+	p.Lock()
+	defer p.Unlock()
+	return p.err
+}
+
+// ClearError forgets the error returned by Err so that commands are sent
+// again, e.g. once the connection is back. Resync clears it too.
+func (p *Printer) ClearError() {
+	p.Lock()
+	defer p.Unlock()
+	p.err = nil
+}
+
+// Write sends buf to the transport as is, retrying short writes.
+// After the first transport failure it returns that error without writing.
 func (p *Printer) Write(buf []byte) (int, error) {
-	return p.t.Write(buf)
+	return p.WriteContext(context.Background(), buf)
 }
 
-func (p *Printer) Init() {
-	p.Reset()
-	p.t.Write([]byte("\x1B@")) // ESC @ (Initialize printer)
+// WriteContext is Write bounded by ctx. If ctx is already done nothing is
+// sent. A write interrupted midway returns the ctx error without failing
+// the printer; as the device may have received part of buf, its formatting
+// is then treated as unknown (see Resync).
+func (p *Printer) WriteContext(ctx context.Context, buf []byte) (int, error) {
+	p.Lock()
+	defer p.Unlock()
+	return p.send(ctx, buf)
 }
 
-func (p *Printer) End() {
-	p.t.Write([]byte("\xFA"))
+// send is WriteContext for callers already holding the lock.
+func (p *Printer) send(ctx context.Context, buf []byte) (int, error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if _, ok := ctx.Deadline(); !ok && p.writeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.writeTimeout)
+		defer cancel()
+	}
+	sent := 0
+	for sent < len(buf) {
+		n, err := p.t.WriteContext(ctx, buf[sent:])
+		sent += n
+		if err == nil && n == 0 {
+			err = io.ErrShortWrite
+		}
+		if err != nil {
+			p.logger.Error("printer write failed", "bytes", sent, "error", err)
+			p.metrics.written(p.name, sent, err)
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				// the caller gave up, the connection is fine; only what
+				// the printer got of buf is unknown, as an abandoned write
+				// may still finish after returning 0
+				p.device, p.userSlots = unknownStyle, nil
+				return sent, err
+			}
+			p.err = err
+			return sent, err
+		}
+	}
+	p.metrics.written(p.name, sent, nil)
+	return sent, nil
+}
+
+// do runs fn with the printer locked and its writes bounded by ctx.
+func (p *Printer) do(ctx context.Context, fn func() error) error {
+	p.Lock()
+	defer p.Unlock()
+	p.ctx = ctx
+	defer func() { p.ctx = nil }()
+	return fn()
+}
+
+// write is send without the byte count, for command helpers; the caller
+// holds the lock.
+func (p *Printer) write(buf []byte) error {
+	ctx := p.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	_, err := p.send(ctx, buf)
+	return err
 }
 
-func (p *Printer) Cut() {
-	p.t.Write([]byte("\x1DVA0")) // GS
+// writeCmd writes the result of a cmd constructor, or returns its
+// validation error.
+func (p *Printer) writeCmd(b []byte, err error) error {
+	if err != nil {
+		return err
+	}
+	return p.write(b)
 }
 
-func (p *Printer) Cash() {
-	p.t.Write([]byte("\x1B\x70\x00\x0A\xFF"))
+// Init sends ESC @, which also resets all formatting, and selects the code
+// page given by WithCodePage.
+func (p *Printer) Init() error {
+	return p.InitContext(context.Background())
 }
 
-func (p *Printer) Linefeed() {
-	p.t.Write([]byte("\n"))
+// InitContext is Init bounded by ctx.
+func (p *Printer) InitContext(ctx context.Context) error {
+	return p.do(ctx, func() error { return p.initialize() })
 }
 
-func (p *Printer) FormfeedN(n int) {
-	p.t.Write([]byte(fmt.Sprintf("\x1Bd%c", n))) // ESC
+func (p *Printer) initialize() error {
+	p.style = defaultStyle
+	if p.hasCodePage {
+		p.style.codePage = int(p.fixedCodePage)
+	}
+	if err := p.write(p.dialect.Init()); err != nil {
+		return err
+	}
+	return p.deviceReset()
 }
 
-func (p *Printer) Formfeed() {
-	p.FormfeedN(1)
+func (p *Printer) End() error {
+	return p.do(context.Background(), func() error { return p.end() })
 }
 
-func (p *Printer) SendFontSize() {
-	p.t.Write([]byte(fmt.Sprintf("\x1D!%c", ((p.width-1)<<4)|(p.height-1))))
+func (p *Printer) end() error {
+	return p.write([]byte("\xFA"))
 }
 
-func (p *Printer) SetFontSize(width, height byte) {
-	if width > 0 && height > 0 && width <= 8 && height <= 8 {
-		p.width, p.height = width, height
-		p.SendFontSize()
-	} else {
-		logInternal.Errlog.Printf("Invalid font size passed: %d x %d\n", width, height)
+func (p *Printer) Cut() error {
+	return p.CutContext(context.Background())
+}
+
+// CutContext is Cut bounded by ctx.
+func (p *Printer) CutContext(ctx context.Context) error {
+	return p.do(ctx, func() error { return p.cut() })
+}
+
+func (p *Printer) cut() error {
+	if !p.profile.Cutter {
+		return fmt.Errorf("%w: %s has no cutter", ErrNotSupported, p.profile.Name)
 	}
+	return p.writeCmd(p.dialect.Cut(false))
+}
+
+func (p *Printer) Cash() error {
+	return p.CashContext(context.Background())
+}
+
+// CashContext is Cash bounded by ctx.
+func (p *Printer) CashContext(ctx context.Context) error {
+	return p.do(ctx, func() error { return p.cash() })
+}
+
+func (p *Printer) cash() error {
+	return p.writeCmd(p.dialect.Drawer(0, 0x0a, 0xff))
+}
+
+func (p *Printer) Linefeed() error {
+	return p.LinefeedContext(context.Background())
+}
+
+// LinefeedContext is Linefeed bounded by ctx.
+func (p *Printer) LinefeedContext(ctx context.Context) error {
+	return p.do(ctx, func() error { return p.linefeed() })
 }
 
-func (p *Printer) SendUnderline() {
-	p.t.Write([]byte(fmt.Sprintf("\x1B-%c", p.underline)))
+func (p *Printer) linefeed() error {
+	return p.write(cmd.LineFeed())
 }
 
-func (p *Printer) SendEmphasize() {
-	p.t.Write([]byte(fmt.Sprintf("\x1BG%c", p.emphasize)))
+func (p *Printer) FormfeedN(n int) error {
+	return p.FormfeedNContext(context.Background(), n)
 }
 
-func (p *Printer) SendUpsidedown() {
-	p.t.Write([]byte(fmt.Sprintf("\x1B{%c", p.upsidedown)))
+// FormfeedNContext is FormfeedN bounded by ctx.
+func (p *Printer) FormfeedNContext(ctx context.Context, n int) error {
+	return p.do(ctx, func() error { return p.formfeedN(n) })
 }
 
-func (p *Printer) SendRotate() {
-	p.t.Write([]byte(fmt.Sprintf("\x1BR%c", p.rotate)))
+func (p *Printer) formfeedN(n int) error {
+	return p.writeCmd(p.dialect.FeedLines(n))
 }
 
-func (p *Printer) SendReverse() {
-	p.t.Write([]byte(fmt.Sprintf("\x1DB%c", p.reverse)))
+func (p *Printer) Formfeed() error {
+	return p.do(context.Background(), func() error { return p.formfeed() })
 }
 
-func (p *Printer) SendSmooth() {
-	p.t.Write([]byte(fmt.Sprintf("\x1Db%c", p.smooth)))
+func (p *Printer) formfeed() error {
+	return p.formfeedN(1)
 }
 
-func (p *Printer) SendMoveX(x uint16) {
-	p.Write([]byte{0x1b, 0x24, byte(x % 256), byte(x / 256)})
+// The Send methods send one formatting command unless the printer is
+// known to have that setting already. They and the Set methods have no
+// Context variants: their few bytes are bounded by WithWriteTimeout, or by
+// ctx when issued through a Job of JobContext.
+
+func (p *Printer) SendFontSize() error {
+	return p.do(context.Background(), func() error { return p.sendFontSize() })
+}
+
+func (p *Printer) sendFontSize() error {
+	if p.device.width == p.width && p.device.height == p.height {
+		return nil
+	}
+	if err := p.writeCmd(p.dialect.CharSize(int(p.width), int(p.height))); err != nil {
+		return err
+	}
+	p.device.width, p.device.height = p.width, p.height
+	return nil
 }
 
-func (p *Printer) SendMoveY(y uint16) {
-	p.Write([]byte{0x1d, 0x24, byte(y % 256), byte(y / 256)})
+func (p *Printer) SetFontSize(width, height byte) error {
+	return p.do(context.Background(), func() error { return p.setFontSize(width, height) })
 }
 
-func (p *Printer) SetUnderline(v byte) {
-	p.underline = v
-	p.SendUnderline()
+func (p *Printer) setFontSize(width, height byte) error {
+	if width == 0 || height == 0 || width > 8 || height > 8 {
+		return fmt.Errorf("invalid font size passed: %d x %d", width, height)
+	}
+	oldWidth, oldHeight := p.width, p.height
+	p.width, p.height = width, height
+	if err := p.sendFontSize(); err != nil {
+		p.width, p.height = oldWidth, oldHeight
+		return err
+	}
+	return nil
 }
 
-func (p *Printer) SetEmphasize(u byte) {
-	p.emphasize = u
-	p.SendEmphasize()
+// SendFont sends ESC M n (font A, B or C).
+func (p *Printer) SendFont() error {
+	return p.do(context.Background(), func() error { return p.sendFont() })
 }
 
-func (p *Printer) SetUpsidedown(v byte) {
-	p.upsidedown = v
-	p.SendUpsidedown()
+func (p *Printer) sendFont() error {
+	return p.update(&p.device.font, p.font, func() ([]byte, error) {
+		// downloaded characters are made for the cell of one font
+		p.userSlots = nil
+		return p.dialect.Font(p.font)
+	})
 }
 
-func (p *Printer) SetRotate(v byte) {
-	p.rotate = v
-	p.SendRotate()
+// SetFont selects the resident font "A", "B" or "C" of the profile.
+func (p *Printer) SetFont(name string) error {
+	return p.do(context.Background(), func() error { return p.setFont(name) })
 }
 
-func (p *Printer) SetReverse(v byte) {
-	p.reverse = v
-	p.SendReverse()
+func (p *Printer) setFont(name string) error {
+	f, ok := p.profile.Font(name)
+	if !ok {
+		return fmt.Errorf("%s has no font %s", p.profile.Name, name)
+	}
+	return p.set(&p.font, strings.ToUpper(f.Name)[0]-'A', p.sendFont)
 }
 
-func (p *Printer) SetSmooth(v byte) {
-	p.smooth = v
-	p.SendSmooth()
+// SendCharSpacing sends ESC SP n (space right of each character).
+func (p *Printer) SendCharSpacing() error {
+	return p.do(context.Background(), func() error { return p.sendCharSpacing() })
 }
 
-func (p *Printer) Pulse() {
-	// with t=2 -- meaning 2*2msec
-	p.t.Write([]byte("\x1Bp\x02"))
+func (p *Printer) sendCharSpacing() error {
+	return p.update(&p.device.spacing, p.spacing, func() ([]byte, error) {
+		return p.dialect.CharSpacing(int(p.spacing))
+	})
 }
 
-func (p *Printer) SetAlign(align string) {
-	a := 0
+// SetCharSpacing sets the space right of each character, in dots; double
+// width doubles it.
+func (p *Printer) SetCharSpacing(dots byte) error {
+	return p.do(context.Background(), func() error { return p.setCharSpacing(dots) })
+}
+
+func (p *Printer) setCharSpacing(dots byte) error {
+	return p.set(&p.spacing, dots, p.sendCharSpacing)
+}
+
+func (p *Printer) SendUnderline() error {
+	return p.do(context.Background(), func() error { return p.sendUnderline() })
+}
+
+func (p *Printer) sendUnderline() error {
+	return p.update(&p.device.underline, p.underline, func() ([]byte, error) {
+		return p.dialect.Underline(p.underline)
+	})
+}
+
+// SendEmphasize sends ESC E n (emphasized, i.e. bold, mode).
+func (p *Printer) SendEmphasize() error {
+	return p.do(context.Background(), func() error { return p.sendEmphasize() })
+}
+
+func (p *Printer) sendEmphasize() error {
+	return p.update(&p.device.emphasize, p.emphasize, func() ([]byte, error) {
+		return p.dialect.Emphasize(p.emphasize == 1)
+	})
+}
+
+// SendDoubleStrike sends ESC G n (double-strike mode).
+func (p *Printer) SendDoubleStrike() error {
+	return p.do(context.Background(), func() error { return p.sendDoubleStrike() })
+}
+
+func (p *Printer) sendDoubleStrike() error {
+	return p.update(&p.device.doubleStrike, p.doubleStrike, func() ([]byte, error) {
+		return p.dialect.DoubleStrike(p.doubleStrike == 1)
+	})
+}
+
+func (p *Printer) SendUpsidedown() error {
+	return p.do(context.Background(), func() error { return p.sendUpsidedown() })
+}
+
+func (p *Printer) sendUpsidedown() error {
+	return p.update(&p.device.upsidedown, p.upsidedown, func() ([]byte, error) {
+		return p.dialect.UpsideDown(p.upsidedown&1 == 1)
+	})
+}
+
+// SendRotate sends ESC V n (90° clockwise rotation).
+func (p *Printer) SendRotate() error {
+	return p.do(context.Background(), func() error { return p.sendRotate() })
+}
+
+func (p *Printer) sendRotate() error {
+	return p.update(&p.device.rotate, p.rotate, func() ([]byte, error) {
+		return p.dialect.Rotate90(p.rotate)
+	})
+}
+
+func (p *Printer) SendReverse() error {
+	return p.do(context.Background(), func() error { return p.sendReverse() })
+}
+
+func (p *Printer) sendReverse() error {
+	return p.update(&p.device.reverse, p.reverse, func() ([]byte, error) {
+		return p.dialect.Reverse(p.reverse&1 == 1)
+	})
+}
+
+func (p *Printer) SendSmooth() error {
+	return p.do(context.Background(), func() error { return p.sendSmooth() })
+}
+
+func (p *Printer) sendSmooth() error {
+	return p.update(&p.device.smooth, p.smooth, func() ([]byte, error) {
+		return p.dialect.Smooth(p.smooth&1 == 1)
+	})
+}
+
+func (p *Printer) SendMoveX(x uint16) error {
+	return p.do(context.Background(), func() error { return p.sendMoveX(x) })
+}
+
+func (p *Printer) sendMoveX(x uint16) error {
+	return p.writeCmd(p.dialect.Position(int(x)))
+}
+
+func (p *Printer) SendMoveY(y uint16) error {
+	return p.do(context.Background(), func() error { return p.sendMoveY(y) })
+}
+
+func (p *Printer) sendMoveY(y uint16) error {
+	return p.writeCmd(p.dialect.VerticalPosition(int(y)))
+}
+
+func (p *Printer) SetUnderline(v byte) error {
+	return p.do(context.Background(), func() error { return p.setUnderline(v) })
+}
+
+func (p *Printer) setUnderline(v byte) error {
+	return p.set(&p.underline, v, p.sendUnderline)
+}
+
+// SetEmphasize turns bold printing on (1) or off (0).
+func (p *Printer) SetEmphasize(u byte) error {
+	return p.do(context.Background(), func() error { return p.setEmphasize(u) })
+}
+
+func (p *Printer) setEmphasize(u byte) error {
+	if u > 1 {
+		return fmt.Errorf("invalid emphasize mode: %d", u)
+	}
+	return p.set(&p.emphasize, u, p.sendEmphasize)
+}
+
+// SetDoubleStrike turns double-strike printing on (1) or off (0).
+func (p *Printer) SetDoubleStrike(v byte) error {
+	return p.do(context.Background(), func() error { return p.setDoubleStrike(v) })
+}
+
+func (p *Printer) setDoubleStrike(v byte) error {
+	if v > 1 {
+		return fmt.Errorf("invalid double-strike mode: %d", v)
+	}
+	return p.set(&p.doubleStrike, v, p.sendDoubleStrike)
+}
+
+func (p *Printer) SetUpsidedown(v byte) error {
+	return p.do(context.Background(), func() error { return p.setUpsidedown(v) })
+}
+
+func (p *Printer) setUpsidedown(v byte) error {
+	return p.set(&p.upsidedown, v, p.sendUpsidedown)
+}
+
+// SetRotate90 turns 90° clockwise rotation off (0) or on (1, or 2 for
+// 1.5-dot character spacing where supported).
+func (p *Printer) SetRotate90(v byte) error {
+	return p.do(context.Background(), func() error { return p.setRotate90(v) })
+}
+
+func (p *Printer) setRotate90(v byte) error {
+	if v > 2 {
+		return fmt.Errorf("invalid rotate mode: %d", v)
+	}
+	return p.set(&p.rotate, v, p.sendRotate)
+}
+
+// SetRotate is the old name of SetRotate90.
+//
+// Deprecated: use SetRotate90; ESC R, which SetRotate used to send, is
+// SetInternationalCharset.
+func (p *Printer) SetRotate(v byte) error {
+	return p.SetRotate90(v)
+}
+
+// SetInternationalCharset selects the international character set with
+// ESC R n (0 USA, 1 France, 2 Germany, ... 17 Slovenia/Croatia, 66–75 and
+// 82 for India and the Arabic sets).
+func (p *Printer) SetInternationalCharset(n byte) error {
+	return p.do(context.Background(), func() error { return p.setInternationalCharset(n) })
+}
+
+func (p *Printer) setInternationalCharset(n byte) error {
+	return p.writeCmd(p.dialect.InternationalCharset(n))
+}
+
+func (p *Printer) SetReverse(v byte) error {
+	return p.do(context.Background(), func() error { return p.setReverse(v) })
+}
+
+func (p *Printer) setReverse(v byte) error {
+	return p.set(&p.reverse, v, p.sendReverse)
+}
+
+func (p *Printer) SetSmooth(v byte) error {
+	return p.do(context.Background(), func() error { return p.setSmooth(v) })
+}
+
+func (p *Printer) setSmooth(v byte) error {
+	return p.set(&p.smooth, v, p.sendSmooth)
+}
+
+// Pulse kicks the cash drawer on pin 2 for 2×2 ms.
+func (p *Printer) Pulse() error {
+	return p.PulseContext(context.Background())
+}
+
+// PulseContext is Pulse bounded by ctx.
+func (p *Printer) PulseContext(ctx context.Context) error {
+	return p.do(ctx, func() error { return p.pulse() })
+}
+
+func (p *Printer) pulse() error {
+	return p.writeCmd(p.dialect.Drawer(0, 2, 2))
+}
+
+func (p *Printer) SetAlign(align string) error {
+	return p.do(context.Background(), func() error { return p.setAlign(align) })
+}
+
+func (p *Printer) setAlign(align string) error {
+	var a byte
 	switch align {
 	case "left":
-		a = 0
+		a = cmd.JustifyLeft
 	case "center":
-		a = 1
+		a = cmd.JustifyCenter
 	case "right":
-		a = 2
+		a = cmd.JustifyRight
 	default:
-		log.Printf("Invalid alignment: %s\n", align)
+		return fmt.Errorf("invalid alignment: %s", align)
 	}
-	p.t.Write([]byte(fmt.Sprintf("\x1Ba%c", a)))
+	p.align = a
+	return p.sendAlign()
+}
+
+func (p *Printer) sendAlign() error {
+	return p.update(&p.device.align, p.align, func() ([]byte, error) {
+		return p.dialect.Align(p.align)
+	})
 }
 
 func (p *Printer) Feed(params map[string]string) error {
+	return p.do(context.Background(), func() error { return p.feed(params) })
+}
+
+func (p *Printer) feed(params map[string]string) error {
 	// handle lines (form feed X lines)
 	if l, ok := params["line"]; ok {
-		if i, err := strconv.Atoi(l); err == nil {
-			p.FormfeedN(i)
-		} else {
+		i, err := strconv.Atoi(l)
+		if err != nil {
+			return err
+		}
+		if err := p.formfeedN(i); err != nil {
 			return err
 		}
 	}
 
 	// handle units (dots)
 	if u, ok := params["unit"]; ok {
-		if i, err := strconv.Atoi(u); err == nil {
-			p.SendMoveY(uint16(i))
-		} else {
+		i, err := strconv.Atoi(u)
+		if err != nil {
+			return err
+		}
+		if err := p.sendMoveY(uint16(i)); err != nil {
 			return err
 		}
 	}
 
 	// send linefeed
-	p.Linefeed()
-
-	// reset variables
-	p.Reset()
+	if err := p.linefeed(); err != nil {
+		return err
+	}
 
-	// reset printer
-	p.SendEmphasize()
-	p.SendRotate()
-	p.SendSmooth()
-	p.SendReverse()
-	p.SendUnderline()
-	p.SendUpsidedown()
-	p.SendFontSize()
-	p.SendUnderline()
+	// reset variables, then the printer where it differs
+	p.reset()
+	return p.syncStyle()
+}
 
-	return nil
+func (p *Printer) FeedAndCut(params map[string]string) error {
+	return p.do(context.Background(), func() error { return p.feedAndCut(params) })
 }
 
-func (p *Printer) FeedAndCut(params map[string]string) {
+func (p *Printer) feedAndCut(params map[string]string) error {
 	if t, ok := params["type"]; ok && t == "feed" {
-		p.Formfeed()
+		if err := p.formfeed(); err != nil {
+			return err
+		}
 	}
-	p.Cut()
+	return p.cut()
 }
 
-func (p *Printer) gSend(m byte, fn byte, data []byte) {
-	l := len(data) + 2
-
-	p.t.Write([]byte("\x1b(L"))
-	p.Write([]byte{byte(l % 256), byte(l / 256), m, fn})
-	p.t.Write([]byte(data))
+// Image prints data, base64 packed 1-bit rows, with the width and height in
+// dots and the optional align of params, in the image command of the
+// profile and dialect.
+func (p *Printer) Image(params map[string]string, data string) error {
+	return p.do(context.Background(), func() error { return p.imageNode(params, data) })
 }
 
-func (p *Printer) Image(params map[string]string, data string) error {
+func (p *Printer) imageNode(params map[string]string, data string) error {
 	// send alignment to printer
 	if align, ok := params["align"]; ok {
-		p.SetAlign(align)
+		if err := p.setAlign(align); err != nil {
+			return err
+		}
 	}
 
 	// get width
 	wstr, ok := params["width"]
 	if !ok {
-		log.Println("No width specified on image")
+		p.logger.Warn("no width specified on image")
 	}
 
 	// get height
 	hstr, ok := params["height"]
 	if !ok {
-		log.Println("No height specified on image")
+		p.logger.Warn("no height specified on image")
 	}
 
 	// convert width
@@ -309,42 +884,36 @@ func (p *Printer) Image(params map[string]string, data string) error {
 		return err
 	}
 
-	log.Printf("Image len:%d w: %d h: %d\n", len(dec), width, height)
+	p.logger.Debug("image", "len", len(dec), "width", width, "height", height)
 
-	header := []byte{
-		byte('0'), 0x01, 0x01, byte('1'),
-	}
-
-	a := append(header, dec...)
-
-	p.gSend(byte('0'), byte('p'), a)
-	p.gSend(byte('0'), byte('2'), []byte{})
+	// packed 1-bit rows, printed with the command the profile and dialect
+	// support, as Raster does
+	return p.raster(width, height, (width+7)/8, dec, p.profile.rasterMode(height))
+}
 
-	return nil
+func (p *Printer) WriteNode(name string, params map[string]string, data string) error {
+	return p.do(context.Background(), func() error { return p.writeNode(name, params, data) })
 }
 
-func (p *Printer) WriteNode(name string, params map[string]string, data string) {
-	cstr := ""
-	if data != "" {
-		str := data
-		if len(data) > 40 {
-			str = fmt.Sprintf("%s ...", data[0:40])
-		}
-		cstr = fmt.Sprintf(" => '%s'", str)
+func (p *Printer) writeNode(name string, params map[string]string, data string) error {
+	str := data
+	if len(data) > 40 {
+		str = fmt.Sprintf("%s ...", data[0:40])
 	}
-	log.Printf("Write: %s => %+v%s\n", name, params, cstr)
+	p.logger.Debug("write node", "name", name, "params", params, "data", str)
 
 	switch name {
 	case "feed":
-		p.Feed(params)
+		return p.feed(params)
 
 	case "cut":
-		p.FeedAndCut(params)
+		return p.feedAndCut(params)
 
 	case "pulse":
-		p.Pulse()
+		return p.pulse()
 
 	case "image":
-		p.Image(params, data)
+		return p.imageNode(params, data)
 	}
+	return nil
 }
@@ -0,0 +1,146 @@
+package printer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AlexStarov/escpos-GoLang-lib/cmd"
+)
+
+func TestSetters(t *testing.T) {
+	tests := []struct {
+		name string
+		set  func(p *Printer) error
+		want []byte
+		err  bool
+	}{
+		{"emphasize on", func(p *Printer) error { return p.SetEmphasize(1) }, []byte{cmd.ESC, 'E', 1}, false},
+		{"emphasize off", func(p *Printer) error { return p.SetEmphasize(0) }, []byte{cmd.ESC, 'E', 0}, false},
+		{"emphasize 2", func(p *Printer) error { return p.SetEmphasize(2) }, nil, true},
+		{"double-strike on", func(p *Printer) error { return p.SetDoubleStrike(1) }, []byte{cmd.ESC, 'G', 1}, false},
+		{"double-strike off", func(p *Printer) error { return p.SetDoubleStrike(0) }, []byte{cmd.ESC, 'G', 0}, false},
+		{"double-strike 2", func(p *Printer) error { return p.SetDoubleStrike(2) }, nil, true},
+		{"rotate off", func(p *Printer) error { return p.SetRotate90(0) }, []byte{cmd.ESC, 'V', 0}, false},
+		{"rotate on", func(p *Printer) error { return p.SetRotate90(1) }, []byte{cmd.ESC, 'V', 1}, false},
+		{"rotate 1.5 dot", func(p *Printer) error { return p.SetRotate90(2) }, []byte{cmd.ESC, 'V', 2}, false},
+		{"rotate 3", func(p *Printer) error { return p.SetRotate90(3) }, nil, true},
+		{"SetRotate", func(p *Printer) error { return p.SetRotate(1) }, []byte{cmd.ESC, 'V', 1}, false},
+		{"charset USA", func(p *Printer) error { return p.SetInternationalCharset(0) }, []byte{cmd.ESC, 'R', 0}, false},
+		{"charset Slovenia", func(p *Printer) error { return p.SetInternationalCharset(17) }, []byte{cmd.ESC, 'R', 17}, false},
+		{"charset India", func(p *Printer) error { return p.SetInternationalCharset(66) }, []byte{cmd.ESC, 'R', 66}, false},
+		{"charset 82", func(p *Printer) error { return p.SetInternationalCharset(82) }, []byte{cmd.ESC, 'R', 82}, false},
+		{"charset 18", func(p *Printer) error { return p.SetInternationalCharset(18) }, nil, true},
+		{"charset 76", func(p *Printer) error { return p.SetInternationalCharset(76) }, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, w := newTestPrinter(t, "epson-tm-t20")
+			err := tt.set(p)
+			if tt.err {
+				if err == nil {
+					t.Fatal("no error")
+				}
+				if w.Len() != 0 {
+					t.Errorf("% x sent with the error", w.Bytes())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(w.Bytes(), tt.want) {
+				t.Errorf("sent % x, want % x", w.Bytes(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSettersSendChangesOnly(t *testing.T) {
+	p, w := newTestPrinter(t, "epson-tm-t20")
+	for _, set := range []func() error{
+		func() error { return p.SetEmphasize(1) },
+		func() error { return p.SetEmphasize(1) },
+		func() error { return p.SetDoubleStrike(1) },
+		func() error { return p.SetDoubleStrike(1) },
+		func() error { return p.SetRotate90(1) },
+		func() error { return p.SetRotate90(1) },
+		func() error { return p.SetEmphasize(0) },
+	} {
+		if err := set(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []byte{cmd.ESC, 'E', 1, cmd.ESC, 'G', 1, cmd.ESC, 'V', 1, cmd.ESC, 'E', 0}
+	if !bytes.Equal(w.Bytes(), want) {
+		t.Errorf("sent % x, want % x", w.Bytes(), want)
+	}
+}
+
+// slowWriter blocks every Write until release is closed.
+type slowWriter struct {
+	bufPrinter
+	release chan struct{}
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return w.bufPrinter.Write(p)
+}
+
+func TestAbandonedWriteForgetsDeviceState(t *testing.T) {
+	w := &slowWriter{release: make(chan struct{})}
+	p, err := NewPrinter(w, WithWriteTimeout(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetEmphasize(1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error %v, want context.DeadlineExceeded", err)
+	}
+	// the abandoned ESC E 1 reaches the printer after all
+	close(w.release)
+	if err := p.SetEmphasize(0); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{cmd.ESC, 'E', 1, cmd.ESC, 'E', 0}
+	if !bytes.Equal(w.Bytes(), want) {
+		t.Errorf("sent % x, want % x", w.Bytes(), want)
+	}
+}
+
+func TestImageNode(t *testing.T) {
+	// 16×2 dots, two bytes a row
+	data := "//8AAA=="
+	tests := []struct {
+		profile string
+		want    []byte
+	}{
+		{"epson-tm-m30", bytes.Join([][]byte{
+			{cmd.GS, '(', 'L', 14, 0, '0', 'p', '0', 1, 1, '1', 16, 0, 2, 0, 0xff, 0xff, 0, 0},
+			{cmd.GS, '(', 'L', 2, 0, '0', '2'},
+		}, nil)},
+		{"xprinter-xp-80", []byte{cmd.GS, 'v', '0', 0, 2, 0, 2, 0, 0xff, 0xff, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			p, w := newTestPrinter(t, tt.profile)
+			if err := p.WriteNode("image", map[string]string{"width": "16", "height": "2"}, data); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(w.Bytes(), tt.want) {
+				t.Errorf("sent % x, want % x", w.Bytes(), tt.want)
+			}
+		})
+	}
+
+	// Star Line Mode has no GS ( L
+	p, w := newTestPrinter(t, "star-tsp100-star")
+	if err := p.WriteNode("image", map[string]string{"width": "16", "height": "2"}, data); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(w.Bytes(), []byte{cmd.GS, '('}) {
+		t.Errorf("sent % x to a Star Line printer", w.Bytes())
+	}
+}
@@ -6,6 +6,6 @@ package printer
 import "fmt"
 
 // NewWinPrintSpoolerPrinter — заглушка для macOS
-func NewWinPrintSpoolerPrinter(printerName string) (*Printer, error) {
-    return nil, fmt.Errorf("Windows Spooler printing is only supported on Windows")
+func NewWinPrintSpoolerPrinter(printerName string, opts ...Option) (*Printer, error) {
+    return nil, fmt.Errorf("%w: Windows Spooler printing is only supported on Windows", ErrNotSupported)
 }
@@ -31,7 +31,7 @@ func (s *spoolerConn) Write(p []byte) (int, error) {
 
 func (s *spoolerConn) Read(p []byte) (int, error) {
 	// Обычно чтение из принтера через спулер не используется
-	return 0, fmt.Errorf("read not supported for Windows spooler connection")
+	return 0, fmt.Errorf("%w: read on Windows spooler connection", ErrNotSupported)
 }
 
 func (s *spoolerConn) Close() error {
@@ -42,7 +42,7 @@ func (s *spoolerConn) Close() error {
 }
 
 // NewWinPrintSpoolerPrinter создает принтер по имени (Windows Spooler)
-func NewWinPrintSpoolerPrinter(printerName string) (*Printer, error) {
+func NewWinPrintSpoolerPrinter(printerName string, opts ...Option) (*Printer, error) {
 	var hPrinter windows.Handle
 	pname, _ := windows.UTF16PtrFromString(printerName)
 	r1, _, err := procOpenPrinter.Call(
@@ -76,7 +76,7 @@ func NewWinPrintSpoolerPrinter(printerName string) (*Printer, error) {
 	procStartPagePrinter.Call(uintptr(hPrinter))
 
 	conn := &spoolerConn{hPrinter: hPrinter}
-	return NewPrinter(conn)
+	return NewPrinter(conn, opts...)
 }
 
 // --- WinAPI binding ---
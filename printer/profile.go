@@ -0,0 +1,221 @@
+package printer
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+//go:embed profiles.json
+var profilesJSON []byte
+
+// Font describes one of the resident fonts of a printer.
+type Font struct {
+	Name    string `json:"name"`    // A, B or C
+	Columns int    `json:"columns"` // characters per line at normal width
+	Width   int    `json:"width"`   // character cell in dots
+	Height  int    `json:"height"`
+}
+
+// CodePageRef ties a code page name to its ESC t number on a printer.
+type CodePageRef struct {
+	Name string `json:"name"`
+	ID   byte   `json:"id"`
+}
+
+// Raster modes of a Profile.
+const (
+	RasterAuto     = "auto"     // GS v 0, GS 8 L for images taller than gs8lMaxY
+	RasterBitImage = "bitImage" // GS v 0 only
+	RasterGraphics = "graphics" // GS 8 L / GS ( L only
+)
+
+// Profile records the capabilities of a printer model.
+type Profile struct {
+	Name   string `json:"name"`
+	Vendor string `json:"vendor"`
+	Model  string `json:"model"`
+
+	// printable line width in dots
+	DotWidth int `json:"dot_width"`
+
+	Fonts     []Font        `json:"fonts"`
+	CodePages []CodePageRef `json:"code_pages"`
+
+	Cutter bool `json:"cutter"`
+	QRCode bool `json:"qr_code"`
+
+	// 1D barcode symbologies, named as in Printer.Barcode; empty means all
+	Barcodes []string `json:"barcodes,omitempty"`
+
+	// preferred image command, one of the Raster* modes
+	Raster string `json:"raster"`
+
+	// command set, one of the Dialect* names; empty means ESC/POS
+	Dialect string `json:"dialect,omitempty"`
+
+	// double-byte encoding of the Kanji / CJK font, one of the Kanji* names;
+	// empty if the printer has none. Only Shift-JIS is selected with FS C;
+	// GB18030, Big5 and KS C 5601 are whatever the regional ROM has.
+	Kanji string `json:"kanji,omitempty"`
+}
+
+// profiles is the embedded database, keyed by profile name.
+var profiles = loadProfiles()
+
+func loadProfiles() map[string]*Profile {
+	var list []*Profile
+	if err := json.Unmarshal(profilesJSON, &list); err != nil {
+		panic("printer: invalid embedded profiles.json: " + err.Error())
+	}
+	m := make(map[string]*Profile, len(list))
+	for _, prof := range list {
+		m[prof.Name] = prof
+	}
+	return m
+}
+
+// DefaultProfile returns the profile used when none is given: a generic
+// 80mm printer with 512 dots per line.
+func DefaultProfile() *Profile {
+	return profiles["default"].clone()
+}
+
+// LookupProfile returns a copy of the named profile, e.g. "epson-tm-t20".
+func LookupProfile(name string) (*Profile, error) {
+	prof, ok := profiles[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown printer profile: %s", name)
+	}
+	return prof.clone(), nil
+}
+
+// ProfileNames lists the names of all embedded profiles.
+func ProfileNames() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (pr *Profile) clone() *Profile {
+	c := *pr
+	c.Fonts = append([]Font(nil), pr.Fonts...)
+	c.CodePages = append([]CodePageRef(nil), pr.CodePages...)
+	c.Barcodes = append([]string(nil), pr.Barcodes...)
+	return &c
+}
+
+// Font returns the resident font with the given name.
+func (pr *Profile) Font(name string) (Font, bool) {
+	for _, f := range pr.Fonts {
+		if strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return Font{}, false
+}
+
+// Columns returns the characters per line of font at normal width, or 0 if
+// the printer has no such font.
+func (pr *Profile) Columns(font string) int {
+	f, _ := pr.Font(font)
+	return f.Columns
+}
+
+// CodePage returns the ESC t number of the named code page.
+func (pr *Profile) CodePage(name string) (byte, bool) {
+	for _, cp := range pr.CodePages {
+		if strings.EqualFold(cp.Name, name) {
+			return cp.ID, true
+		}
+	}
+	return 0, false
+}
+
+// AddCodePage makes the named code page available as ESC t id, replacing
+// any code page with the same name or number. The name must have a table
+// in the codepage package, or be given to WithCodeTable, for Text to use it.
+func (pr *Profile) AddCodePage(name string, id byte) {
+	pr.CodePages = slices.DeleteFunc(pr.CodePages, func(cp CodePageRef) bool {
+		return cp.ID == id || strings.EqualFold(cp.Name, name)
+	})
+	pr.CodePages = append(pr.CodePages, CodePageRef{Name: name, ID: id})
+}
+
+// hasCodePageID reports whether id is the ESC t number of one of the code
+// pages of the profile.
+func (pr *Profile) hasCodePageID(id byte) bool {
+	for _, cp := range pr.CodePages {
+		if cp.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// hasBarcode reports whether the printer prints the named symbology.
+func (pr *Profile) hasBarcode(name string) bool {
+	if len(pr.Barcodes) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(pr.Barcodes, func(b string) bool {
+		return strings.EqualFold(b, name)
+	})
+}
+
+// rasterMode picks the Printer.Raster printing type for an image of the
+// given height.
+func (pr *Profile) rasterMode(height int) string {
+	switch pr.Raster {
+	case RasterBitImage, RasterGraphics:
+		return pr.Raster
+	}
+	if height >= gs8lMaxY {
+		return RasterGraphics
+	}
+	return RasterBitImage
+}
+
+// WithPaperWidth returns a copy of the profile for paper of the given width
+// in mm (58 or 80). A profile made for that paper is kept as it is, as its
+// printable width may differ from the usual one, e.g. 512 dots on 80mm for
+// the TM-T88. Otherwise 203 dpi is assumed: the printable width becomes
+// 48mm (384 dots) or 72mm (576 dots) and font columns follow from the cell
+// widths.
+func (pr *Profile) WithPaperWidth(mm int) (*Profile, error) {
+	var dots int
+	switch mm {
+	case 58:
+		dots = 384
+	case 80:
+		dots = 576
+	default:
+		return nil, fmt.Errorf("unsupported paper width: %dmm", mm)
+	}
+	c := pr.clone()
+	if pr.DotWidth > 0 && pr.paperWidth() == mm {
+		return c, nil
+	}
+	c.DotWidth = dots
+	for i, f := range c.Fonts {
+		if f.Width > 0 {
+			c.Fonts[i].Columns = dots / f.Width
+		}
+	}
+	return c, nil
+}
+
+// paperWidth returns the paper the printable width is made for: 58mm up to
+// 432 dots (54mm at 203 dpi), 80mm above.
+func (pr *Profile) paperWidth() int {
+	if pr.DotWidth <= 432 {
+		return 58
+	}
+	return 80
+}
@@ -0,0 +1,105 @@
+package printer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/AlexStarov/escpos-GoLang-lib/cmd"
+)
+
+// RawOption configures WriteRaw.
+type RawOption func(*rawOptions)
+
+type rawOptions struct {
+	dropUnsupported bool
+}
+
+// DropUnsupported makes WriteRaw remove the commands the profile does not
+// support instead of rejecting the stream.
+func DropUnsupported() RawOption {
+	return func(o *rawOptions) { o.dropUnsupported = true }
+}
+
+// WriteRaw sends a pre-built ESC/POS stream, such as one from a third-party
+// system, after checking it.
+//
+// The whole stream is parsed first: a truncated or unknown command fails
+// the call with a *cmd.ParseError and nothing is sent. Commands the profile
+// does not support fail it with ErrNotSupported, unless DropUnsupported is
+// given. The stream is sent as one Job followed by ESC S, so the printer is
+// always left in standard mode, FS . if the stream turned Kanji mode on,
+// and the Printer's own formatting, so whatever the stream changed does not
+// leak into later output.
+func (p *Printer) WriteRaw(b []byte, opts ...RawOption) error {
+	return p.WriteRawContext(context.Background(), b, opts...)
+}
+
+// WriteRawContext is WriteRaw with the write bounded by ctx.
+func (p *Printer) WriteRawContext(ctx context.Context, b []byte, opts ...RawOption) error {
+	var o rawOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if _, ok := p.dialect.(EscPos); !ok {
+		return fmt.Errorf("%w: raw ESC/POS on a %T printer", ErrNotSupported, p.dialect)
+	}
+
+	tokens, err := cmd.Parse(b)
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	kanji := false
+	for _, t := range tokens {
+		if err := p.rawSupported(t); err != nil {
+			if !o.dropUnsupported {
+				return err
+			}
+			p.logger.Debug("raw command dropped", "command", t.Name, "bytes", len(t.Bytes))
+			continue
+		}
+		kanji = kanji || t.Name == "FS &"
+		out.Write(t.Bytes)
+	}
+
+	return p.JobContext(ctx, func(j *Job) error {
+		if err := j.write(out.Bytes()); err != nil {
+			return err
+		}
+		if err := j.write(cmd.StandardMode()); err != nil {
+			return err
+		}
+		// Resync cancels Kanji mode only on printers with a Kanji font
+		if kanji && j.kanji == nil {
+			if err := j.write(cmd.CancelKanjiMode()); err != nil {
+				return err
+			}
+		}
+		return j.Resync()
+	})
+}
+
+// rawSupported checks one command of a raw stream against the profile.
+func (p *Printer) rawSupported(t cmd.Token) error {
+	unsupported := false
+	switch t.Name {
+	case "GS V":
+		unsupported = !p.profile.Cutter
+	case "GS ( k":
+		unsupported = !p.profile.QRCode
+	case "GS k":
+		unsupported = !p.profile.hasBarcode(barcodeSymbology(t.Bytes[2]))
+	case "GS ( L", "GS 8 L":
+		unsupported = p.profile.Raster == RasterBitImage || p.dialect.(EscPos).NoGraphics
+	case "GS v":
+		unsupported = p.profile.Raster == RasterGraphics
+	case "ESC t":
+		unsupported = !p.profile.hasCodePageID(t.Bytes[2])
+	}
+	if unsupported {
+		return fmt.Errorf("%w: %s on %s", ErrNotSupported, t.Name, p.profile.Name)
+	}
+	return nil
+}
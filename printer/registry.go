@@ -0,0 +1,287 @@
+package printer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/AlexStarov/escpos-GoLang-lib/codepage"
+)
+
+// PrinterConfig describes one named printer of a Registry.
+type PrinterConfig struct {
+	Name       string `json:"name" yaml:"name"`
+	Connection string `json:"connection" yaml:"connection"` // DSN, see Open
+	Profile    string `json:"profile,omitempty" yaml:"profile,omitempty"`
+	Dialect    string `json:"dialect,omitempty" yaml:"dialect,omitempty"` // overrides the profile's, see LookupDialect
+	CodePage   string `json:"code_page,omitempty" yaml:"code_page,omitempty"`
+	Kanji      string `json:"kanji,omitempty" yaml:"kanji,omitempty"`             // see WithKanji
+	PaperWidth int    `json:"paper_width,omitempty" yaml:"paper_width,omitempty"` // mm, see Profile.WithPaperWidth
+
+	CodeTables []CodeTableConfig `json:"code_tables,omitempty" yaml:"code_tables,omitempty"`
+}
+
+// CodeTableConfig adds a code page to a registry printer, see WithCodeTable.
+// Without File the name must be a table of the codepage package.
+type CodeTableConfig struct {
+	Name string `json:"name" yaml:"name"`
+	ID   byte   `json:"id" yaml:"id"`                         // ESC t number
+	File string `json:"file,omitempty" yaml:"file,omitempty"` // mapping file, see codepage.Parse
+}
+
+// registryFile is the layout of a registry config file.
+type registryFile struct {
+	Printers []PrinterConfig `json:"printers" yaml:"printers"`
+}
+
+// Registry holds named printers. Each printer is opened on first use and
+// reused afterwards.
+type Registry struct {
+	mu       sync.Mutex
+	configs  map[string]PrinterConfig
+	printers map[string]*Printer
+	// printers being opened, closed when done
+	opening map[string]chan struct{}
+	opts    []Option
+	// set by Close; printers opened after it are closed again
+	closed bool
+}
+
+// ErrRegistryClosed is returned by Get after Registry.Close.
+var ErrRegistryClosed = errors.New("printer registry closed")
+
+// LoadRegistry reads printer definitions from a .json, .yaml or .yml file
+// of the form
+//
+//	printers:
+//	  - name: kitchen
+//	    connection: tcp://10.0.0.5:9100
+//	    profile: epson-tm-t20
+//	    code_page: CP866
+//	    paper_width: 80
+//
+// opts are passed to every printer after the settings from the file.
+func LoadRegistry(path string, opts ...Option) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f registryFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &f)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &f)
+	default:
+		return nil, fmt.Errorf("unknown registry file type: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return NewRegistry(f.Printers, opts...)
+}
+
+// NewRegistry builds a Registry from printer definitions. Nothing is opened
+// until Get.
+func NewRegistry(configs []PrinterConfig, opts ...Option) (*Registry, error) {
+	r := &Registry{
+		configs:  make(map[string]PrinterConfig, len(configs)),
+		printers: make(map[string]*Printer),
+		opening:  make(map[string]chan struct{}),
+		opts:     opts,
+	}
+	for _, c := range configs {
+		if c.Name == "" {
+			return nil, errors.New("registry printer without a name")
+		}
+		if c.Connection == "" {
+			return nil, fmt.Errorf("registry printer %s has no connection", c.Name)
+		}
+		if _, dup := r.configs[c.Name]; dup {
+			return nil, fmt.Errorf("registry printer %s is defined twice", c.Name)
+		}
+		if _, err := c.options(); err != nil {
+			return nil, fmt.Errorf("registry printer %s: %w", c.Name, err)
+		}
+		r.configs[c.Name] = c
+	}
+	return r, nil
+}
+
+// Get returns the named printer, opening it on first use.
+func (r *Registry) Get(name string) (*Printer, error) {
+	return r.GetContext(context.Background(), name)
+}
+
+// GetContext is Get with ctx bounding the connect. Printers are opened
+// without holding up Gets of other names; concurrent Gets of the same name
+// wait for the one connect and retry it if it failed.
+func (r *Registry) GetContext(ctx context.Context, name string) (*Printer, error) {
+	for {
+		r.mu.Lock()
+		if r.closed {
+			r.mu.Unlock()
+			return nil, ErrRegistryClosed
+		}
+		if p, ok := r.printers[name]; ok {
+			r.mu.Unlock()
+			return p, nil
+		}
+		c, ok := r.configs[name]
+		if !ok {
+			r.mu.Unlock()
+			return nil, fmt.Errorf("unknown printer: %s", name)
+		}
+		done, busy := r.opening[name]
+		if !busy {
+			done = make(chan struct{})
+			r.opening[name] = done
+			r.mu.Unlock()
+			return r.open(ctx, c, done)
+		}
+		r.mu.Unlock()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// open connects to the printer of c and wakes the Gets waiting on done.
+func (r *Registry) open(ctx context.Context, c PrinterConfig, done chan struct{}) (*Printer, error) {
+	defer close(done)
+
+	opts, err := c.options()
+	var p *Printer
+	if err == nil {
+		p, err = Open(ctx, c.Connection, append(opts, r.opts...)...)
+	}
+
+	r.mu.Lock()
+	delete(r.opening, c.Name)
+	if err != nil {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("printer %s: %w", c.Name, err)
+	}
+	if r.closed {
+		r.mu.Unlock()
+		p.CloseConnection()
+		return nil, ErrRegistryClosed
+	}
+	r.printers[c.Name] = p
+	r.mu.Unlock()
+	return p, nil
+}
+
+// Names lists the configured printers.
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.configs))
+	for name := range r.configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Forget closes the named printer if it is open, so the next Get
+// reconnects. Gets of other printers do not wait for the close, which for
+// LPD sends the job and waits for the acknowledgement.
+func (r *Registry) Forget(name string) error {
+	r.mu.Lock()
+	p, ok := r.printers[name]
+	delete(r.printers, name)
+	r.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	return p.CloseConnection()
+}
+
+// Close closes every open printer and returns the first error. Printers
+// still being opened are closed as soon as they connect, and later Gets
+// fail with ErrRegistryClosed.
+func (r *Registry) Close() error {
+	r.mu.Lock()
+	r.closed = true
+	printers := r.printers
+	r.printers = make(map[string]*Printer)
+	r.mu.Unlock()
+
+	names := make([]string, 0, len(printers))
+	for name := range printers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var first error
+	for _, name := range names {
+		if err := printers[name].CloseConnection(); err != nil && first == nil {
+			first = fmt.Errorf("printer %s: %w", name, err)
+		}
+	}
+	return first
+}
+
+func (c PrinterConfig) options() ([]Option, error) {
+	opts := []Option{WithName(c.Name)}
+
+	if c.Profile != "" || c.PaperWidth != 0 {
+		prof := DefaultProfile()
+		if c.Profile != "" {
+			var err error
+			if prof, err = LookupProfile(c.Profile); err != nil {
+				return nil, err
+			}
+		}
+		if c.PaperWidth != 0 {
+			var err error
+			if prof, err = prof.WithPaperWidth(c.PaperWidth); err != nil {
+				return nil, err
+			}
+		}
+		opts = append(opts, WithProfile(prof))
+	}
+	if c.Dialect != "" {
+		d, err := LookupDialect(c.Dialect)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithDialect(d))
+	}
+	for _, ct := range c.CodeTables {
+		t, ok := codepage.Lookup(ct.Name)
+		if ct.File != "" {
+			var err error
+			if t, err = codepage.LoadFile(ct.Name, ct.File); err != nil {
+				return nil, err
+			}
+		} else if !ok {
+			return nil, fmt.Errorf("unknown code table: %s", ct.Name)
+		}
+		opts = append(opts, WithCodeTable(t, ct.ID))
+	}
+	if c.CodePage != "" {
+		opts = append(opts, WithCodePage(c.CodePage))
+	}
+	if c.Kanji != "" {
+		if _, err := lookupKanji(c.Kanji); err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithKanji(c.Kanji))
+	}
+	return opts, nil
+}
@@ -0,0 +1,115 @@
+package printer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// listen accepts connections on a local port and hands each to serve.
+func listen(t *testing.T, serve func(net.Conn)) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serve(conn)
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestRegistryForgetDoesNotBlockGet(t *testing.T) {
+	requested := make(chan struct{}, 1)
+	release := make(chan struct{})
+	// an LPD server that takes the job request and never acknowledges it
+	lpd := listen(t, func(conn net.Conn) {
+		defer conn.Close()
+		b := make([]byte, 1)
+		if _, err := conn.Read(b); err != nil {
+			return
+		}
+		requested <- struct{}{}
+		<-release
+	})
+	raw := listen(t, func(conn net.Conn) { io.Copy(io.Discard, conn) })
+
+	r, err := NewRegistry([]PrinterConfig{
+		{Name: "lpd", Connection: "lpd://" + lpd + "/q"},
+		{Name: "raw", Connection: "tcp://" + raw},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	p, err := r.Get("lpd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Text("job\n"); err != nil {
+		t.Fatal(err)
+	}
+	forgot := make(chan error)
+	go func() { forgot <- r.Forget("lpd") }()
+	<-requested
+
+	got := make(chan error)
+	go func() {
+		_, err := r.Get("raw")
+		got <- err
+	}()
+	select {
+	case err := <-got:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Get waited for Forget of another printer")
+	}
+	close(release)
+	<-forgot
+}
+
+func TestRegistryCloseDuringOpen(t *testing.T) {
+	closed := make(chan struct{})
+	addr := listen(t, func(conn net.Conn) {
+		io.Copy(io.Discard, conn)
+		close(closed)
+	})
+	r, err := NewRegistry([]PrinterConfig{{Name: "raw", Connection: "tcp://" + addr}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Close lands while the connect is in flight
+	done := make(chan struct{})
+	r.opening["raw"] = done
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.open(context.Background(), r.configs["raw"], done); !errors.Is(err, ErrRegistryClosed) {
+		t.Fatalf("open error %v, want ErrRegistryClosed", err)
+	}
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("the printer opened after Close was left connected")
+	}
+	if len(r.printers) != 0 {
+		t.Errorf("printers %v kept after Close", r.printers)
+	}
+	if _, err := r.Get("raw"); !errors.Is(err, ErrRegistryClosed) {
+		t.Errorf("Get error %v, want ErrRegistryClosed", err)
+	}
+}
@@ -6,65 +6,63 @@ import (
 
     "go.bug.st/serial"
 
-    logInternal "github.com/AlexStarov/escpos-GoLang-lib/log"
 )
 
 // NewSerialPrinter создаёт и инициализирует ESC/POS-принтер через последовательный порт.
-func NewSerialPrinter(portName string, baudRate uint64) (*Printer, error) {
+func NewSerialPrinter(portName string, baudRate uint64, opts ...Option) (*Printer, error) {
+    return newSerialPrinter(portName, &serial.Mode{
+        BaudRate: int(baudRate),
+        DataBits: 8,
+        Parity:   serial.NoParity,
+        StopBits: serial.OneStopBit,
+    }, opts...)
+}
+
+// newSerialPrinter — NewSerialPrinter с произвольными параметрами порта.
+func newSerialPrinter(portName string, mode *serial.Mode, opts ...Option) (*Printer, error) {
+    log := loggerFrom(opts).With("port", portName)
+
     // Получаем список доступных COM-портов
     ports, err := serial.GetPortsList()
     if err != nil {
-        logInternal.Errlog.Printf("Ошибка получения списка портов: %v", err)
         return nil, fmt.Errorf("failed to list serial ports: %w", err)
     }
-    logInternal.Stdlog.Printf("Доступные порты: %v", ports)
+    log.Debug("serial ports listed", "ports", ports)
 
     // Проверяем, что указанный порт существует
     if !contains(ports, portName) {
-        logInternal.Errlog.Printf("Порт %s не найден", portName)
         return nil, fmt.Errorf("serial port %s not found", portName)
     }
-    logInternal.Stdlog.Printf("Используем порт: %s", portName)
-
-    // Конфигурируем параметры подключения
-    mode := &serial.Mode{
-        BaudRate: int(baudRate),
-        DataBits: 8,
-        Parity:   serial.NoParity,
-        StopBits: serial.OneStopBit,
-    }
 
     // Открываем порт
     serialPort, err := serial.Open(portName, mode)
     if err != nil {
-        logInternal.Errlog.Printf("Не удалось открыть порт %s: %v", portName, err)
         return nil, fmt.Errorf("failed to open serial port %s: %w", portName, err)
     }
-    logInternal.Stdlog.Printf("Порт %s успешно открыт", portName)
+    log.Debug("serial port opened", "baud", mode.BaudRate)
 
     // Устанавливаем таймаут чтения
     serialPort.SetReadTimeout(100 * time.Millisecond)
 
     // Создаём объект Printer
-    printer, err := NewPrinter(serialPort)
+    printer, err := NewPrinter(serialPort, opts...)
     if err != nil {
         serialPort.Close()
-        logInternal.Errlog.Printf("Ошибка инициализации Printer: %v", err)
         return nil, err
     }
 
     // Отправляем XON для разблокировки приёма
     if n, err := serialPort.Write([]byte{0x11}); err != nil {
-        logInternal.Errlog.Printf("Не удалось отправить XON: %v", err)
+        log.Warn("failed to send XON", "error", err)
     } else {
-        logInternal.Stdlog.Printf("Отправлено XON (%d байт)", n)
+        log.Debug("sent XON", "bytes", n)
     }
 
     // ESC @ — полная инициализация принтера
     if n, err := serialPort.Write([]byte{0x1B, 0x40}); err != nil {
-        logInternal.Errlog.Printf("Не удалось отправить ESC @: %v", err)
+        log.Warn("failed to send ESC @", "error", err)
     } else {
-        logInternal.Stdlog.Printf("Отправлено ESC @ (%d байт)", n)
+        log.Debug("sent ESC @", "bytes", n)
     }
 
     // Даем принтеру время на завершение инициализации
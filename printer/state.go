@@ -0,0 +1,178 @@
+package printer
+
+import (
+	"context"
+	"errors"
+)
+
+// style is the formatting state of a printer: what the caller asked for
+// (Printer.style) or what the device has (Printer.device).
+type style struct {
+	// font metrics
+	width, height byte
+	// ESC M n and ESC SP n
+	font, spacing byte
+
+	// state toggles ESC[char]
+	underline    byte
+	emphasize    byte
+	doubleStrike byte
+	upsidedown   byte
+	rotate       byte
+
+	// state toggles GS[char]
+	reverse, smooth byte
+
+	// ESC a n
+	align byte
+
+	// ESC t n, or -1 for none chosen or not known
+	codePage int
+
+	// FS & (1) and FS .; Text leaves Kanji mode off, so only the device
+	// state varies
+	kanjiMode byte
+}
+
+// defaultStyle is the state of a printer after ESC @. The code page then
+// depends on the printer's memory switches, so it is not known.
+var defaultStyle = style{width: 1, height: 1, codePage: -1}
+
+// unknownStyle marks the device state as unknown: no field matches a valid
+// value, so the next sync sends every command.
+var unknownStyle = style{
+	width: 0xff, height: 0xff, font: 0xff, spacing: 0xff,
+	underline: 0xff, emphasize: 0xff, doubleStrike: 0xff, upsidedown: 0xff, rotate: 0xff,
+	reverse: 0xff, smooth: 0xff,
+	align:     0xff,
+	codePage:  -1,
+	kanjiMode: 0xff,
+}
+
+// errNoSavedState is returned by RestoreState without a matching SaveState.
+var errNoSavedState = errors.New("RestoreState without SaveState")
+
+// update sends the command built by build for one style field unless the
+// device already has want, and records want as the device state once the
+// command is written.
+func (p *Printer) update(have *byte, want byte, build func() ([]byte, error)) error {
+	if *have == want {
+		return nil
+	}
+	if err := p.writeCmd(build()); err != nil {
+		return err
+	}
+	*have = want
+	return nil
+}
+
+// set changes one style field and sends it with send; the field keeps its
+// old value if the command cannot be built or written.
+func (p *Printer) set(field *byte, v byte, send func() error) error {
+	old := *field
+	*field = v
+	if err := send(); err != nil {
+		*field = old
+		return err
+	}
+	return nil
+}
+
+// syncStyle sends the commands needed to bring the device to p.style.
+func (p *Printer) syncStyle() error {
+	for _, send := range []func() error{
+		p.sendFontSize,
+		p.sendFont,
+		p.sendCharSpacing,
+		p.sendUnderline,
+		p.sendEmphasize,
+		p.sendDoubleStrike,
+		p.sendUpsidedown,
+		p.sendRotate,
+		p.sendReverse,
+		p.sendSmooth,
+		p.sendAlign,
+		p.sendCodePage,
+		p.sendKanjiMode,
+	} {
+		if err := send(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Resync forgets what the printer is known to have, clears Err and sends
+// the full formatting state again, e.g. after the printer was power cycled
+// or the connection was re-established behind the Printer.
+//
+// Resync is never called automatically. A Printer does not reconnect by
+// itself; one from Open or from Registry.Get after Registry.Forget starts
+// with the device state unknown, so each setting is sent the first time it
+// is set. A Transport that reconnects internally must be followed by a call
+// to Resync.
+func (p *Printer) Resync() error {
+	return p.ResyncContext(context.Background())
+}
+
+// ResyncContext is Resync bounded by ctx.
+func (p *Printer) ResyncContext(ctx context.Context) error {
+	return p.do(ctx, func() error { return p.resync() })
+}
+
+func (p *Printer) resync() error {
+	p.err = nil
+	p.device, p.userSlots = unknownStyle, nil
+	return p.syncStyle()
+}
+
+// deviceReset records that the printer went back to its power-on state,
+// as after ESC @, and sends the formatting state again; the caller holds
+// the lock. Chinese, Taiwanese and Korean models power up in Kanji mode and
+// ESC @ does not cancel it, so printers with a Kanji font get FS . as well.
+func (p *Printer) deviceReset() error {
+	p.device, p.userSlots = defaultStyle, nil
+	if p.kanji != nil {
+		p.device.kanjiMode = 0xff
+	}
+	return p.syncStyle()
+}
+
+// sendKanjiMode sends FS . unless the printer is known to be out of Kanji
+// mode. Printers without a Kanji font are taken to be out of it.
+func (p *Printer) sendKanjiMode() error {
+	if p.kanji == nil {
+		p.device.kanjiMode = 0
+		return nil
+	}
+	return p.update(&p.device.kanjiMode, 0, func() ([]byte, error) {
+		return p.dialect.KanjiMode(false)
+	})
+}
+
+// SaveState pushes the current formatting so that a helper can change it
+// and undo the change with RestoreState.
+func (p *Printer) SaveState() {
+	p.Lock()
+	defer p.Unlock()
+	p.saveState()
+}
+
+func (p *Printer) saveState() {
+	p.saved = append(p.saved, p.style)
+}
+
+// RestoreState pops the formatting saved by the last SaveState and sends
+// what differs from the current one.
+func (p *Printer) RestoreState() error {
+	return p.do(context.Background(), func() error { return p.restoreState() })
+}
+
+func (p *Printer) restoreState() error {
+	if len(p.saved) == 0 {
+		return errNoSavedState
+	}
+	p.style = p.saved[len(p.saved)-1]
+	p.saved = p.saved[:len(p.saved)-1]
+	return p.syncStyle()
+}
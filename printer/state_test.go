@@ -0,0 +1,106 @@
+package printer
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/AlexStarov/escpos-GoLang-lib/cmd"
+)
+
+func TestResyncSendsFullState(t *testing.T) {
+	p, w := newTestPrinter(t, "epson-tm-t20", WithCodePage("CP866"))
+	for _, set := range []func() error{
+		func() error { return p.SetEmphasize(1) },
+		func() error { return p.SetUnderline(1) },
+		func() error { return p.SetAlign("center") },
+		func() error { return p.SetFontSize(2, 2) },
+	} {
+		if err := set(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w.Reset()
+	if err := p.Resync(); err != nil {
+		t.Fatal(err)
+	}
+	want := bytes.Join([][]byte{
+		{cmd.GS, '!', 0x11},
+		{cmd.ESC, 'M', 0},
+		{cmd.ESC, ' ', 0},
+		{cmd.ESC, '-', 1},
+		{cmd.ESC, 'E', 1},
+		{cmd.ESC, 'G', 0},
+		{cmd.ESC, '{', 0},
+		{cmd.ESC, 'V', 0},
+		{cmd.GS, 'B', 0},
+		{cmd.GS, 'b', 0},
+		{cmd.ESC, 'a', 1},
+		{cmd.ESC, 't', 17},
+	}, nil)
+	if !bytes.Equal(w.Bytes(), want) {
+		t.Errorf("Resync sent % x, want % x", w.Bytes(), want)
+	}
+
+	// the state is known again
+	w.Reset()
+	if err := p.SetEmphasize(1); err != nil {
+		t.Fatal(err)
+	}
+	if w.Len() != 0 {
+		t.Errorf("SetEmphasize(1) after Resync sent % x", w.Bytes())
+	}
+}
+
+func TestResyncClearsErr(t *testing.T) {
+	p, w := newTestPrinter(t, "epson-tm-t20")
+	p.err = errors.New("connection lost")
+	if err := p.SetEmphasize(1); err == nil {
+		t.Fatal("no error before Resync")
+	}
+	if err := p.Resync(); err != nil {
+		t.Fatal(err)
+	}
+	if p.Err() != nil {
+		t.Errorf("Err %v after Resync", p.Err())
+	}
+	// the failed SetEmphasize left the requested state as it was
+	if !bytes.Contains(w.Bytes(), []byte{cmd.ESC, 'E', 0}) {
+		t.Errorf("Resync sent % x, want ESC E 0", w.Bytes())
+	}
+	w.Reset()
+	if err := p.SetEmphasize(1); err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{cmd.ESC, 'E', 1}; !bytes.Equal(w.Bytes(), want) {
+		t.Errorf("SetEmphasize(1) sent % x, want % x", w.Bytes(), want)
+	}
+}
+
+func TestInitResetsState(t *testing.T) {
+	p, w := newTestPrinter(t, "epson-tm-t20", WithCodePage("CP866"))
+	if err := p.SetEmphasize(1); err != nil {
+		t.Fatal(err)
+	}
+
+	w.Reset()
+	if err := p.Init(); err != nil {
+		t.Fatal(err)
+	}
+	// ESC @ restores the defaults, so only the fixed code page is sent
+	if want := []byte{cmd.ESC, '@', cmd.ESC, 't', 17}; !bytes.Equal(w.Bytes(), want) {
+		t.Errorf("Init sent % x, want % x", w.Bytes(), want)
+	}
+
+	w.Reset()
+	if err := p.SetEmphasize(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetEmphasize(1); err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{cmd.ESC, 'E', 1}; !bytes.Equal(w.Bytes(), want) {
+		t.Errorf("setters after Init sent % x, want % x", w.Bytes(), want)
+	}
+}
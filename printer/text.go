@@ -0,0 +1,197 @@
+package printer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AlexStarov/escpos-GoLang-lib/codepage"
+)
+
+// textReplacement is the default replacement for characters no code page
+// has.
+const textReplacement = '?'
+
+// Text prints s, a UTF-8 string, in the code pages of the printer,
+// selecting them with ESC t only when needed.
+//
+// Characters stay in the current code page while it has them. When it does
+// not, Text switches to the profile code page that has the longest run of
+// the characters that follow, so a line mixing scripts prints with as few
+// switches as possible. With WithCodePage only that code page is used.
+// Characters no code page has print in Kanji mode when the printer has a
+// Kanji font that has them (see Profile.Kanji), else as user-defined
+// characters when there is a glyph for them, built in for ₽, ₸ and ₴ or
+// given by WithUserChar; the rest are replaced by the WithTextFallback
+// function, '?' by default.
+// Pure ASCII is sent as is.
+func (p *Printer) Text(s string) error {
+	return p.TextContext(context.Background(), s)
+}
+
+// TextContext is Text bounded by ctx.
+func (p *Printer) TextContext(ctx context.Context, s string) error {
+	return p.do(ctx, func() error { return p.text(s) })
+}
+
+func (p *Printer) text(s string) error {
+	if isASCII(s) {
+		return p.write([]byte(s))
+	}
+	pages, err := p.textCodePages()
+	if err != nil {
+		return err
+	}
+
+	cur := slices.IndexFunc(pages, func(cp textCodePage) bool { return int(cp.ref.ID) == p.codePage })
+	if cur >= 0 {
+		// the printer may not have the chosen page yet, e.g. before Init
+		if err := p.sendCodePage(); err != nil {
+			return err
+		}
+	}
+	runes := []rune(s)
+	var buf []byte
+	// user-defined characters printed after this keep their glyph
+	userStart := p.userCharClock()
+	// selectPage sends what buf has so far and switches to pages[next]
+	selectPage := func(next int) error {
+		if err := p.write(buf); err != nil {
+			return err
+		}
+		buf = buf[:0]
+		cur, p.codePage = next, int(pages[next].ref.ID)
+		return p.sendCodePage()
+	}
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if cur >= 0 {
+			if b, ok := pages[cur].table.Encode(r); ok {
+				buf = append(buf, b)
+				continue
+			}
+		} else if r < utf8.RuneSelf {
+			buf = append(buf, byte(r))
+			continue
+		}
+
+		next := pickCodePage(pages, runes[i:])
+		if next < 0 {
+			var n int
+			if buf, n, err = p.textKanji(buf, runes[i:], pages); err != nil {
+				return err
+			}
+			if n == 0 {
+				if buf, n, err = p.textUserChars(buf, runes[i:], pages, userStart); err != nil {
+					return err
+				}
+			}
+			if n == 0 {
+				if cur < 0 {
+					// the replacement is encoded in the first page
+					if err := selectPage(0); err != nil {
+						return err
+					}
+				}
+				buf = append(buf, p.textFallback(r, pages[cur])...)
+			}
+			i += max(n, 1) - 1
+			continue
+		}
+		if err := selectPage(next); err != nil {
+			return err
+		}
+		b, _ := pages[cur].table.Encode(r)
+		buf = append(buf, b)
+	}
+	return p.write(buf)
+}
+
+// textCodePage is a code page of the profile that has a character table.
+type textCodePage struct {
+	ref   CodePageRef
+	table *codepage.Table
+}
+
+// textCodePages returns the code pages Text may use, in profile order.
+func (p *Printer) textCodePages() ([]textCodePage, error) {
+	var pages []textCodePage
+	for _, ref := range p.profile.CodePages {
+		if p.hasCodePage && ref.ID != p.fixedCodePage {
+			continue
+		}
+		if t, ok := p.lookupTable(ref.Name); ok {
+			pages = append(pages, textCodePage{ref, t})
+		} else if p.hasCodePage {
+			return nil, fmt.Errorf("%w: no character table for code page %s", ErrNotSupported, ref.Name)
+		}
+	}
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("%w: no code page of %s has a character table", ErrNotSupported, p.profile.Name)
+	}
+	return pages, nil
+}
+
+// lookupTable finds the character table of a code page, preferring those
+// of WithCodeTable.
+func (p *Printer) lookupTable(name string) (*codepage.Table, bool) {
+	for _, t := range p.codeTables {
+		if strings.EqualFold(t.Name(), name) {
+			return t, true
+		}
+	}
+	return codepage.Lookup(name)
+}
+
+// pickCodePage returns the page that encodes the longest prefix of runes,
+// the first one on a tie, or -1 if none has runes[0].
+func pickCodePage(pages []textCodePage, runes []rune) int {
+	best, bestLen := -1, 0
+	for i, cp := range pages {
+		n := 0
+		for _, r := range runes {
+			if _, ok := cp.table.Encode(r); !ok {
+				break
+			}
+			n++
+		}
+		if n > bestLen {
+			best, bestLen = i, n
+		}
+	}
+	return best
+}
+
+// textFallback encodes the replacement of r, a character no code page
+// has, in the selected page cp.
+func (p *Printer) textFallback(r rune, cp textCodePage) []byte {
+	s := string(textReplacement)
+	if p.fallback != nil {
+		s = p.fallback(r)
+	}
+	return cp.table.EncodeString(s, textReplacement)
+}
+
+// sendCodePage sends ESC t for the chosen code page unless the printer is
+// known to have it selected.
+func (p *Printer) sendCodePage() error {
+	if p.codePage < 0 || p.device.codePage == p.codePage {
+		return nil
+	}
+	if err := p.write(p.dialect.CodeTable(byte(p.codePage))); err != nil {
+		return err
+	}
+	p.device.codePage = p.codePage
+	return nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
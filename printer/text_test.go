@@ -0,0 +1,63 @@
+package printer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/AlexStarov/escpos-GoLang-lib/cmd"
+	"github.com/AlexStarov/escpos-GoLang-lib/codepage"
+)
+
+func TestTextCodePageRuns(t *testing.T) {
+	georgian := codepage.FromMap("Georgian", map[byte]rune{0x80: 'ა'})
+	esct := func(n byte) []byte { return []byte{cmd.ESC, 't', n} }
+	cat := func(parts ...[]byte) []byte { return bytes.Join(parts, nil) }
+
+	tests := []struct {
+		name string
+		s    string
+		opts []Option
+		want []byte
+	}{
+		{"ASCII", "abc", nil, []byte("abc")},
+		{"one page", "Привет", nil, cat(esct(17), []byte{0x8f, 0xe0, 0xa8, 0xa2, 0xa5, 0xe2})},
+		{"switch per run", "Привет Ελλάδα", nil, cat(
+			esct(17), []byte{0x8f, 0xe0, 0xa8, 0xa2, 0xa5, 0xe2, ' '},
+			esct(14), []byte{0x84, 0xa2, 0xa2, 0xe1, 0x9b, 0x98},
+		)},
+		{"switch back", "é Привет é", nil, cat(
+			esct(0), []byte{0x82, ' '},
+			esct(17), []byte{0x8f, 0xe0, 0xa8, 0xa2, 0xa5, 0xe2, ' '},
+			esct(0), []byte{0x82},
+		)},
+		{"custom table", "ა", []Option{WithCodeTable(georgian, 99)}, cat(esct(99), []byte{0x80})},
+		{"fallback then custom", "Ⴀა", []Option{WithCodeTable(georgian, 99)}, cat(esct(0), []byte{'?'}, esct(99), []byte{0x80})},
+		{"fixed page", "Привет é", []Option{WithCodePage("CP866")}, cat(
+			esct(17), []byte{0x8f, 0xe0, 0xa8, 0xa2, 0xa5, 0xe2, ' ', '?'},
+		)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, w := newTestPrinter(t, "epson-tm-t20", tt.opts...)
+			if err := p.Text(tt.s); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(w.Bytes(), tt.want) {
+				t.Errorf("sent % x, want % x", w.Bytes(), tt.want)
+			}
+		})
+	}
+}
+
+func TestTextKeepsCodePage(t *testing.T) {
+	p, w := newTestPrinter(t, "epson-tm-t20")
+	for _, s := range []string{"При", "вет"} {
+		if err := p.Text(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []byte{cmd.ESC, 't', 17, 0x8f, 0xe0, 0xa8, 0xa2, 0xa5, 0xe2}
+	if !bytes.Equal(w.Bytes(), want) {
+		t.Errorf("sent % x, want % x", w.Bytes(), want)
+	}
+}
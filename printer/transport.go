@@ -2,9 +2,11 @@ package printer
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net"
 	"os"
 	"strconv"
@@ -17,24 +19,55 @@ type Transport interface {
 	Write([]byte) (int, error)
 	Read([]byte) (int, error)
 	Close() error
+
+	// WriteContext and ReadContext are Write and Read that give up once
+	// ctx is cancelled or its deadline passes, returning ctx.Err().
+	WriteContext(ctx context.Context, b []byte) (int, error)
+	ReadContext(ctx context.Context, b []byte) (int, error)
 }
 
 // -------------------- RAW --------------------
 
 type RawTransport struct {
 	conn io.ReadWriteCloser
+	// operations abandoned by WriteContext and ReadContext
+	async awaiter
+}
+
+func (r *RawTransport) Write(b []byte) (int, error) {
+	n, err := r.conn.Write(b)
+	return n, transportError("write", err)
+}
+
+func (r *RawTransport) Read(b []byte) (int, error) {
+	n, err := r.conn.Read(b)
+	return n, transportError("read", err)
 }
 
-func (r *RawTransport) Write(b []byte) (int, error) { return r.conn.Write(b) }
-func (r *RawTransport) Read(b []byte) (int, error)  { return r.conn.Read(b) }
-func (r *RawTransport) Close() error                { return r.conn.Close() }
+func (r *RawTransport) Close() error { return r.conn.Close() }
+
+func (r *RawTransport) WriteContext(ctx context.Context, b []byte) (int, error) {
+	n, err := writeContext(ctx, r.conn, b, &r.async)
+	return n, transportError("write", err)
+}
+
+func (r *RawTransport) ReadContext(ctx context.Context, b []byte) (int, error) {
+	n, err := readContext(ctx, r.conn, b, &r.async)
+	return n, transportError("read", err)
+}
 
 type LPDTransport struct {
 	conn   net.Conn
 	queue  string
+	user   string
 	jobBuf bytes.Buffer
 	closed bool
 	mu     sync.Mutex
+	logger *slog.Logger
+
+	// printer name and metrics for LPD stage failures
+	name    string
+	metrics *Metrics
 }
 
 func NewLPDTransport(conn net.Conn, queue string) *LPDTransport {
@@ -42,33 +75,38 @@ func NewLPDTransport(conn net.Conn, queue string) *LPDTransport {
 		queue = "lp"
 	}
 	return &LPDTransport{
-		conn:  conn,
-		queue: queue,
+		conn:   conn,
+		queue:  queue,
+		logger: discardLogger,
 	}
 }
 
 func (l *LPDTransport) Write(data []byte) (int, error) {
-
-	if len(data) > 100 {
-		first := fmt.Sprintf("% X", data[:50])
-		last := fmt.Sprintf("% X", data[len(data)-50:])
-		log.Printf("[DEBUG] Write called:\n%s\n...\n%s", first, last)
-	} else {
-		log.Printf("[DEBUG] Write called: % X", data)
-	}
-
 	l.mu.Lock()
 	defer l.mu.Unlock()
 	if l.closed {
-		return 0, io.ErrClosedPipe
+		return 0, transportError("write", io.ErrClosedPipe)
 	}
-	n, err := l.jobBuf.Write(data)
-	log.Printf("[DEBUG] jobBuf.Len() now = %d", l.jobBuf.Len())
-	return n, err
+	return l.jobBuf.Write(data)
 }
 
 func (l *LPDTransport) Read(b []byte) (int, error) {
-	return l.conn.Read(b)
+	n, err := l.conn.Read(b)
+	return n, transportError("read", err)
+}
+
+// WriteContext only appends to the job buffer, so ctx is checked up front;
+// the job itself goes out on Close.
+func (l *LPDTransport) WriteContext(ctx context.Context, data []byte) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return l.Write(data)
+}
+
+func (l *LPDTransport) ReadContext(ctx context.Context, b []byte) (int, error) {
+	n, err := readContext(ctx, l.conn, b, nil)
+	return n, transportError("read", err)
 }
 
 func (l *LPDTransport) Close() error {
@@ -79,21 +117,17 @@ func (l *LPDTransport) Close() error {
 	}
 	defer func() { l.closed = true }()
 
-	log.Printf("[DEBUG] LPDTransport.Close(): jobBuf.Len()=%d", l.jobBuf.Len())
-
 	if l.jobBuf.Len() == 0 {
-		log.Printf("[DEBUG] jobBuf пуст — просто закрываем соединение")
+		// jobBuf пуст — просто закрываем соединение
 		return l.conn.Close()
 	}
 
-	log.Printf("[DEBUG] Отправляем задание в LPD...")
 	if err := l.flushJob(); err != nil {
-		log.Printf("[DEBUG] flushJob() вернул ошибку: %v", err)
+		l.logger.Error("lpd job failed", "queue", l.queue, "error", err)
 		_ = l.conn.Close()
 		return err
 	}
 
-	log.Printf("[DEBUG] Закрываем соединение после flushJob()")
 	return l.conn.Close()
 }
 
@@ -102,7 +136,10 @@ func (l *LPDTransport) flushJob() error {
 	if host == "" {
 		host = "localhost"
 	}
-	user := os.Getenv("USER")
+	user := l.user
+	if user == "" {
+		user = os.Getenv("USER")
+	}
 	if user == "" {
 		user = "GoLang"
 	}
@@ -124,24 +161,29 @@ func (l *LPDTransport) flushJob() error {
 		host, user, jobName, dfName, dfName, dfName,
 	)
 
-	log.Printf("[DEBUG] Stage 1: requestPrintJob")
+	log := l.logger.With("queue", l.queue, "job", jobName)
+
+	log.Debug("lpd stage 1: request print job")
 	if err := requestPrintJob(l.conn, l.queue); err != nil {
+		l.metrics.lpdFailed(l.name, "1")
 		return fmt.Errorf("LPD: stage 1 failed: %w", err)
 	}
 
-	log.Printf("[DEBUG] Stage 2: sendControlFile")
+	log.Debug("lpd stage 2: control file", "bytes", len(control))
 	if err := sendControlFile(l.conn, cfName, []byte(control)); err != nil {
+		l.metrics.lpdFailed(l.name, "2")
 		return fmt.Errorf("LPD: stage 2 failed: %w", err)
 	}
 
 	// Этап 3: файл данных (точный размер из буфера)
 	data := l.jobBuf.Bytes()
-	log.Printf("[DEBUG] Stage 3: sendDataFile (len=%d)", len(data))
+	log.Debug("lpd stage 3: data file", "bytes", len(data))
 	if err := sendDataFile(l.conn, l.queue, dfName, data); err != nil {
+		l.metrics.lpdFailed(l.name, "3")
 		return fmt.Errorf("LPD: stage 3 failed: %w", err)
 	}
 
-	log.Printf("[DEBUG] All stages LPD finished successfully")
+	log.Debug("lpd job sent", "bytes", len(data))
 
 	// Успех — очистим буфер
 	l.jobBuf.Reset()
@@ -152,8 +194,6 @@ func (l *LPDTransport) flushJob() error {
 
 func requestPrintJob(conn net.Conn, queue string) error {
 	// \x02 + <queue>\n
-	log.Printf("[DEBUG] requestPrintJob: conn=%v", conn)
-	log.Printf("[DEBUG] requestPrintJob: queue=%q", queue)
 	if err := writeAll(conn, []byte{0x02}); err != nil {
 		return err
 	}
@@ -222,14 +262,12 @@ func readAck(conn net.Conn, stage string) error {
 	defer conn.SetReadDeadline(time.Time{})
 
 	ack := make([]byte, 1)
-	log.Printf("[DEBUG] Waiting for ACK (%s)...", stage)
 	n, err := conn.Read(ack)
 	if err != nil {
-		return fmt.Errorf("ERROR reading ACK on %s: %w", stage, err)
+		return &LPDError{Stage: stage, Err: transportError("read", err)}
 	}
-	log.Printf("[DEBUG] Received ACK byte: 0x%02x (n=%d) on %s", ack[0], n, stage)
 	if n != 1 || ack[0] != 0x00 {
-		return fmt.Errorf("LPD request not acknowledged on %s", stage)
+		return &LPDError{Stage: stage, NAK: ack[0]}
 	}
 	return nil
 }
@@ -239,13 +277,192 @@ func writeAll(conn net.Conn, b []byte) error {
 	for sent < len(b) {
 		n, err := conn.Write(b[sent:])
 		if err != nil {
-			return err
+			return transportError("write", err)
 		}
 		sent += n
 	}
 	return nil
 }
 
+// -------------------- context helpers --------------------
+
+// contextReadWriter is implemented by connections with native context
+// support, such as usbConn.
+type contextReadWriter interface {
+	ReadContext(ctx context.Context, b []byte) (int, error)
+	WriteContext(ctx context.Context, b []byte) (int, error)
+}
+
+// writeDeadliner and readDeadliner are implemented by net.Conn.
+type writeDeadliner interface {
+	SetWriteDeadline(t time.Time) error
+}
+
+type readDeadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// writeContext and readContext bound one call on conn by ctx. Connections
+// that cannot be interrupted go through a, which must be the same for all
+// calls on conn; it is not used for net.Conn.
+func writeContext(ctx context.Context, conn io.Writer, b []byte, a *awaiter) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	switch c := conn.(type) {
+	case contextReadWriter:
+		return c.WriteContext(ctx, b)
+	case writeDeadliner:
+		return withDeadline(ctx, c.SetWriteDeadline, func() (int, error) { return conn.Write(b) })
+	}
+	return a.write(ctx, conn, b)
+}
+
+func readContext(ctx context.Context, conn io.Reader, b []byte, a *awaiter) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	switch c := conn.(type) {
+	case contextReadWriter:
+		return c.ReadContext(ctx, b)
+	case readDeadliner:
+		return withDeadline(ctx, c.SetReadDeadline, func() (int, error) { return conn.Read(b) })
+	}
+	return a.read(ctx, conn, b)
+}
+
+// withDeadline maps ctx onto a connection deadline: the ctx deadline is set
+// before op, and cancellation moves the deadline into the past so a blocked
+// op returns at once. The connection deadline may fire before ctx notices
+// its own, so a deadline error is reported as ctx.Err() either way.
+func withDeadline(ctx context.Context, set func(time.Time) error, op func() (int, error)) (int, error) {
+	d, hasDeadline := ctx.Deadline()
+	if hasDeadline {
+		_ = set(d)
+	}
+	fired := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		_ = set(time.Unix(1, 0))
+		close(fired)
+	})
+	n, err := op()
+	if !stop() {
+		// the past deadline must not land after the reset below
+		<-fired
+	}
+	_ = set(time.Time{})
+	switch {
+	case err == nil:
+	case ctx.Err() != nil:
+		err = ctx.Err()
+	case hasDeadline && errors.Is(err, os.ErrDeadlineExceeded):
+		err = context.DeadlineExceeded
+	}
+	return n, err
+}
+
+// awaiter runs the calls of a connection that cannot be interrupted
+// (serial ports, the Windows spooler, plain io.ReadWriters) in their own
+// goroutine, so that the caller gets ctx.Err() back as soon as ctx is done.
+// An abandoned call keeps running on a private copy of the buffer; the next
+// call waits for it to return and drops what it read, so late bytes never
+// reach the caller's buffer or a later status query.
+type awaiter struct {
+	mu sync.Mutex
+	// closed when the last abandoned call returns, nil if there is none
+	pending chan struct{}
+}
+
+func (a *awaiter) write(ctx context.Context, w io.Writer, b []byte) (int, error) {
+	if err := a.wait(ctx); err != nil {
+		return 0, err
+	}
+	if ctx.Done() == nil {
+		return w.Write(b)
+	}
+	b = bytes.Clone(b)
+	return a.run(ctx, func() (int, error) { return w.Write(b) })
+}
+
+func (a *awaiter) read(ctx context.Context, r io.Reader, b []byte) (int, error) {
+	if err := a.wait(ctx); err != nil {
+		return 0, err
+	}
+	if ctx.Done() == nil {
+		return r.Read(b)
+	}
+	buf := make([]byte, len(b))
+	n, err := a.run(ctx, func() (int, error) { return r.Read(buf) })
+	return copy(b, buf[:n]), err
+}
+
+// wait blocks until the last abandoned call has returned.
+func (a *awaiter) wait(ctx context.Context) error {
+	a.mu.Lock()
+	pending := a.pending
+	a.mu.Unlock()
+	if pending == nil {
+		return nil
+	}
+	select {
+	case <-pending:
+		a.mu.Lock()
+		if a.pending == pending {
+			a.pending = nil
+		}
+		a.mu.Unlock()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (a *awaiter) run(ctx context.Context, op func() (int, error)) (int, error) {
+	var n int
+	var err error
+	done := make(chan struct{})
+	go func() {
+		n, err = op()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return n, err
+	case <-ctx.Done():
+		a.mu.Lock()
+		a.pending = done
+		a.mu.Unlock()
+		return 0, ctx.Err()
+	}
+}
+
+// -------------------- BUFFER --------------------
+
+// BufferTransport keeps what is written to it in memory, for building
+// commands without a printer: Document.Compile and Job print through one.
+// Nothing ever answers, so reads return io.EOF.
+type BufferTransport struct {
+	buf bytes.Buffer
+}
+
+func (b *BufferTransport) Write(p []byte) (int, error) { return b.buf.Write(p) }
+
+func (b *BufferTransport) Read([]byte) (int, error) { return 0, io.EOF }
+
+func (b *BufferTransport) Close() error { return nil }
+
+func (b *BufferTransport) WriteContext(ctx context.Context, p []byte) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return b.buf.Write(p)
+}
+
+func (b *BufferTransport) ReadContext(context.Context, []byte) (int, error) { return 0, io.EOF }
+
+// Bytes returns everything written so far.
+func (b *BufferTransport) Bytes() []byte { return b.buf.Bytes() }
+
 // -------------------- helpers --------------------
 
 type nopCloser struct {
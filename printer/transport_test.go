@@ -0,0 +1,47 @@
+package printer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithDeadline(t *testing.T) {
+	t.Run("connection deadline first", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+		_, err := withDeadline(ctx, func(time.Time) error { return nil }, func() (int, error) {
+			return 0, os.ErrDeadlineExceeded
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("error %v, want context.DeadlineExceeded", err)
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var mu sync.Mutex
+		var deadlines []time.Time
+		set := func(d time.Time) error {
+			mu.Lock()
+			defer mu.Unlock()
+			deadlines = append(deadlines, d)
+			return nil
+		}
+		_, err := withDeadline(ctx, set, func() (int, error) {
+			cancel()
+			return 0, os.ErrDeadlineExceeded
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("error %v, want context.Canceled", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if len(deadlines) == 0 || !deadlines[len(deadlines)-1].IsZero() {
+			t.Errorf("deadlines %v, want the reset last", deadlines)
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package printer
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -30,14 +31,20 @@ type usbConn struct {
 // NewUSBPrinter создаёт Printer через USB, принимая vendorID и productID.
 // Он настраивает USB-соединение и возвращает Printer, которому "безразлично"
 // – USB это или сетевой принтер, поскольку далее используется универсальный интерфейс io.ReadWriteCloser.
-func NewUSBPrinter(vendorID, productID gousb.ID) (*Printer, error) {
+func NewUSBPrinter(vendorID, productID gousb.ID, opts ...Option) (*Printer, error) {
+	return newUSBPrinter(vendorID, productID, "", opts...)
+}
+
+// newUSBPrinter — NewUSBPrinter с выбором устройства по серийному номеру,
+// если serial не пустой.
+func newUSBPrinter(vendorID, productID gousb.ID, serial string, opts ...Option) (*Printer, error) {
 	// Инициализируем USB контекст.
 	ctx := gousb.NewContext()
 	// Включаем автодетач, чтобы libusb автоматически отключал kernel драйвер для захвата интерфейса.
 	ctx.Debug(0) // Можно выставить уровень дебага, если нужно.
 
 	// Ищем устройство с заданными идентификаторами.
-	dev, err := findUSBPrinter(ctx, vendorID, productID)
+	dev, err := findUSBPrinter(ctx, vendorID, productID, serial)
 	if err != nil {
 		ctx.Close()
 		return nil, err
@@ -95,7 +102,7 @@ func NewUSBPrinter(vendorID, productID gousb.ID) (*Printer, error) {
 
 	// Используем существующий конструктор Printer, передавая нашу обёртку,
 	// которая реализует io.ReadWriteCloser.
-	printer, err := NewPrinter(uc)
+	printer, err := NewPrinter(uc, opts...)
 	if err != nil {
 		uc.Close()
 		return nil, err
@@ -107,10 +114,26 @@ func NewUSBPrinter(vendorID, productID gousb.ID) (*Printer, error) {
 // Read пытается прочитать данные через inEndpoint, если он доступен.
 func (u *usbConn) Read(p []byte) (int, error) {
 	if u.in != nil {
-		return u.in.Read(p)
+		n, err := u.in.Read(p)
+		return n, usbError("read", err)
 	}
 	// Можно вернуть ошибку или нулевое значение, если чтение не реализовано
-	return 0, errors.New("read not supported on USB connection")
+	return 0, fmt.Errorf("%w: read on USB connection without IN endpoint", ErrNotSupported)
+}
+
+// ReadContext — Read с поддержкой отмены через ctx.
+func (u *usbConn) ReadContext(ctx context.Context, p []byte) (int, error) {
+	if u.in != nil {
+		n, err := u.in.ReadContext(ctx, p)
+		return n, usbError("read", err)
+	}
+	return 0, fmt.Errorf("%w: read on USB connection without IN endpoint", ErrNotSupported)
+}
+
+// WriteContext — Write с поддержкой отмены через ctx.
+func (u *usbConn) WriteContext(ctx context.Context, p []byte) (int, error) {
+	n, err := u.out.WriteContext(ctx, p)
+	return n, usbError("write", err)
 }
 
 // Close закрывает все уровни USB подключения.
@@ -133,18 +156,56 @@ func (u *usbConn) Close() error {
 
 // Write отправляет данные через outEndpoint.
 func (u *usbConn) Write(p []byte) (int, error) {
-	return u.out.Write(p)
+	n, err := u.out.Write(p)
+	return n, usbError("write", err)
 }
 
-func findUSBPrinter(ctx *gousb.Context, vendorID, productID gousb.ID) (*gousb.Device, error) {
+// usbError классифицирует ошибки libusb как TransportError.
+func usbError(op string, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gousb.ErrorTimeout), errors.Is(err, gousb.TransferTimedOut):
+		return &TransportError{Op: op, Kind: ErrTimeout, Err: err}
+	case errors.Is(err, gousb.ErrorNoDevice), errors.Is(err, gousb.TransferNoDevice):
+		return &TransportError{Op: op, Kind: ErrOffline, Err: err}
+	}
+	return transportError(op, err)
+}
+
+func findUSBPrinter(ctx *gousb.Context, vendorID, productID gousb.ID, serial string) (*gousb.Device, error) {
 	devs, err := ctx.OpenDevices(func(desc *gousb.DeviceDesc) bool {
 		return desc.Vendor == vendorID && desc.Product == productID
 	})
 	if err != nil {
+		for _, d := range devs {
+			d.Close()
+		}
 		return nil, err
 	}
-	if len(devs) == 0 {
+
+	// Возвращаем первое подходящее устройство, остальные закрываем
+	var found *gousb.Device
+	for _, d := range devs {
+		if found == nil && (serial == "" || usbSerial(d) == serial) {
+			found = d
+			continue
+		}
+		d.Close()
+	}
+	if found == nil {
+		if serial != "" {
+			return nil, fmt.Errorf("USB device %s:%s with serial %s not found", vendorID, productID, serial)
+		}
 		return nil, fmt.Errorf("USB device %s:%s not found", vendorID, productID)
 	}
-	return devs[0], nil // Возвращаем первое найденное устройство
+	return found, nil
+}
+
+func usbSerial(d *gousb.Device) string {
+	s, err := d.SerialNumber()
+	if err != nil {
+		return ""
+	}
+	return s
 }
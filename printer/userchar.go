@@ -0,0 +1,243 @@
+package printer
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/nfnt/resize"
+
+	"github.com/AlexStarov/escpos-GoLang-lib/cmd"
+)
+
+// ESC & character codes available for downloaded glyphs.
+const (
+	userCharFirst = 32
+	userCharLast  = 126
+)
+
+// userCharFont holds the built-in glyphs for characters that few code pages
+// have, drawn for the 12×24 cell of font A and scaled for others; '#' prints.
+// It is not a font: the three currency signs are drawn by hand, and other
+// characters need WithUserChar.
+var userCharFont = map[rune][]string{
+	'₽': {
+		"............",
+		"............",
+		"............",
+		"..#######...",
+		"..########..",
+		"..##....###.",
+		"..##.....##.",
+		"..##.....##.",
+		"..##.....##.",
+		"..##....###.",
+		".#########..",
+		".########...",
+		"..##........",
+		"..##........",
+		".#######....",
+		".#######....",
+		"..##........",
+		"..##........",
+		"..##........",
+		"..##........",
+	},
+	'₸': {
+		"............",
+		"............",
+		"............",
+		".##########.",
+		".##########.",
+		"............",
+		".##########.",
+		".##########.",
+		".....##.....",
+		".....##.....",
+		".....##.....",
+		".....##.....",
+		".....##.....",
+		".....##.....",
+		".....##.....",
+		".....##.....",
+		".....##.....",
+		".....##.....",
+		".....##.....",
+		".....##.....",
+	},
+	'₴': {
+		"............",
+		"............",
+		"............",
+		"...#####....",
+		"..##...##...",
+		".##.....##..",
+		".........##.",
+		".........##.",
+		".##########.",
+		".##########.",
+		".......##...",
+		".....##.....",
+		".##########.",
+		".##########.",
+		"..##........",
+		".##.........",
+		".##.....##..",
+		"..##...##...",
+		"...#####....",
+		"............",
+	},
+}
+
+// userSlot is a user-defined character the printer has: its ESC & code and
+// when Text last printed it, for evicting the least recently used one.
+type userSlot struct {
+	code byte
+	used uint64
+}
+
+// userGlyph returns the image Text prints for r through a user-defined
+// character, if there is one.
+func (p *Printer) userGlyph(r rune) (image.Image, bool) {
+	if img, ok := p.userGlyphs[r]; ok {
+		return img, true
+	}
+	rows, ok := userCharFont[r]
+	if !ok {
+		return nil, false
+	}
+	img := image.NewGray(image.Rect(0, 0, len(rows[0]), len(rows)))
+	for y, row := range rows {
+		for x := 0; x < len(row); x++ {
+			if row[x] != '#' {
+				img.SetGray(x, y, color.Gray{Y: 0xff})
+			}
+		}
+	}
+	return img, true
+}
+
+// textUserChars appends to buf the run of characters at the start of runes
+// that no code page has but a glyph exists for, downloading glyphs the
+// printer does not have yet, and returns how many characters it took. When
+// all codes are taken, the least recently printed character is redefined,
+// unless it was printed after start, by the same Text call: the glyph is
+// looked up when the line prints, so that character would change too.
+// It takes none if the command set has no user-defined characters.
+func (p *Printer) textUserChars(buf []byte, runes []rune, pages []textCodePage, start uint64) ([]byte, int, error) {
+	on, err := p.dialect.UserChars(true)
+	if err != nil {
+		return buf, 0, nil
+	}
+	off, _ := p.dialect.UserChars(false)
+
+	clock := p.userCharClock()
+
+	var codes []byte
+	for _, r := range runes {
+		if pickCodePage(pages, []rune{r}) >= 0 {
+			break
+		}
+		clock++
+		if s, ok := p.userSlots[r]; ok {
+			p.userSlots[r] = userSlot{s.code, clock}
+			codes = append(codes, s.code)
+			continue
+		}
+		img, ok := p.userGlyph(r)
+		if !ok {
+			break
+		}
+		c, ok := p.freeUserChar(start)
+		if !ok {
+			break
+		}
+		y, ch := p.userCharBitmap(img)
+		def, err := p.dialect.DefineUserChar(c, y, ch)
+		if err != nil {
+			return buf, 0, err
+		}
+		buf = append(buf, def...)
+		if p.userSlots == nil {
+			p.userSlots = map[rune]userSlot{}
+		}
+		p.userSlots[r] = userSlot{c, clock}
+		codes = append(codes, c)
+	}
+	if len(codes) == 0 {
+		return buf, 0, nil
+	}
+	buf = append(buf, on...)
+	buf = append(buf, codes...)
+	buf = append(buf, off...)
+	return buf, len(codes), nil
+}
+
+// userCharClock returns when a user-defined character was last printed, in
+// the counts of userSlot.used.
+func (p *Printer) userCharClock() uint64 {
+	var clock uint64
+	for _, s := range p.userSlots {
+		clock = max(clock, s.used)
+	}
+	return clock
+}
+
+// freeUserChar returns an unused ESC & code or, if all are taken, frees the
+// one printed least recently, unless that was after start.
+func (p *Printer) freeUserChar(start uint64) (byte, bool) {
+	var taken [userCharLast + 1]bool
+	var victim rune
+	oldest := start + 1
+	for r, s := range p.userSlots {
+		taken[s.code] = true
+		if s.used < oldest {
+			victim, oldest = r, s.used
+		}
+	}
+	for c := userCharFirst; c <= userCharLast; c++ {
+		if !taken[c] {
+			return byte(c), true
+		}
+	}
+	if oldest > start {
+		return 0, false
+	}
+	c := p.userSlots[victim].code
+	delete(p.userSlots, victim)
+	return c, true
+}
+
+// userCharBitmap renders img for ESC & in the cell of the current font:
+// scaled down to fit and centred vertically, in columns of y bytes with the
+// top dot in the high bit. Light and transparent pixels stay blank.
+func (p *Printer) userCharBitmap(img image.Image) (y byte, ch cmd.UserChar) {
+	cell := p.currentFont()
+	if cell.Width == 0 || cell.Height == 0 {
+		cell = Font{Width: 12, Height: 24}
+	}
+	y = byte(min((cell.Height+7)/8, 3))
+	height := int(y) * 8
+
+	b := img.Bounds()
+	if b.Dx() > cell.Width || b.Dy() > height {
+		img = resize.Thumbnail(uint(cell.Width), uint(height), img, resize.Bilinear)
+		b = img.Bounds()
+	}
+	top := (height - b.Dy()) / 2
+
+	ch.Width = byte(max(b.Dx(), 1))
+	ch.Data = make([]byte, int(ch.Width)*int(y))
+	for x := 0; x < b.Dx(); x++ {
+		for row := 0; row < b.Dy(); row++ {
+			c := img.At(b.Min.X+x, b.Min.Y+row)
+			if _, _, _, a := c.RGBA(); a < 0x8000 {
+				continue // transparent
+			}
+			if color.GrayModel.Convert(c).(color.Gray).Y < 0x80 {
+				dot := top + row
+				ch.Data[x*int(y)+dot/8] |= 0x80 >> uint(dot%8)
+			}
+		}
+	}
+	return y, ch
+}
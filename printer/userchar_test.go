@@ -0,0 +1,83 @@
+package printer
+
+import (
+	"bytes"
+	"image"
+	"testing"
+
+	"github.com/AlexStarov/escpos-GoLang-lib/cmd"
+)
+
+// defines reports whether b downloads a font A glyph to code c.
+func defines(b []byte, c byte) bool {
+	return bytes.Contains(b, []byte{cmd.ESC, '&', 3, c, c})
+}
+
+func TestUserCharsEvictLeastRecentlyUsed(t *testing.T) {
+	// one private use character for every ESC & code
+	const n = userCharLast - userCharFirst + 1
+	glyph := image.NewGray(image.Rect(0, 0, 12, 24))
+	var opts []Option
+	for i := range n {
+		opts = append(opts, WithUserChar(0xe000+rune(i), glyph))
+	}
+	p, w := newTestPrinter(t, "epson-tm-t20", opts...)
+	for i := range n {
+		if err := p.Text(string(0xe000 + rune(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !defines(w.Bytes(), userCharFirst) || !defines(w.Bytes(), userCharLast) {
+		t.Fatal("the first and last codes were not used")
+	}
+
+	// a glyph already downloaded is reused, and is now the most recent
+	w.Reset()
+	if err := p.Text(""); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(w.Bytes(), []byte{cmd.ESC, '&'}) {
+		t.Errorf("downloaded a glyph the printer has: % x", w.Bytes())
+	}
+
+	// all codes are taken: ₽ replaces U+E001, the least recently used
+	w.Reset()
+	if err := p.Text("₽"); err != nil {
+		t.Fatal(err)
+	}
+	if !defines(w.Bytes(), userCharFirst+1) {
+		t.Errorf("₽ sent % x, want it at code %#x", w.Bytes()[:6], userCharFirst+1)
+	}
+
+	// U+E001 comes back in place of U+E002
+	w.Reset()
+	if err := p.Text(""); err != nil {
+		t.Fatal(err)
+	}
+	if !defines(w.Bytes(), userCharFirst+2) {
+		t.Errorf("U+E001 sent % x, want it at code %#x", w.Bytes()[:6], userCharFirst+2)
+	}
+}
+
+func TestUserCharsKeepGlyphsOfTheSameRun(t *testing.T) {
+	glyph := image.NewGray(image.Rect(0, 0, 12, 24))
+	const n = userCharLast - userCharFirst + 1
+	var opts []Option
+	var s []rune
+	for i := range n + 1 {
+		opts = append(opts, WithUserChar(0xe000+rune(i), glyph))
+		s = append(s, 0xe000+rune(i))
+	}
+	p, w := newTestPrinter(t, "epson-tm-t20", opts...)
+	// one more character than there are codes: the last one cannot evict
+	// a glyph printed in the same run
+	if err := p.Text(string(s)); err != nil {
+		t.Fatal(err)
+	}
+	if got := bytes.Count(w.Bytes(), []byte{cmd.ESC, '&'}); got != n {
+		t.Errorf("%d glyphs downloaded, want %d", got, n)
+	}
+	if !bytes.HasSuffix(w.Bytes(), []byte{'?'}) {
+		t.Errorf("sent ...% x, want the last character replaced", w.Bytes()[max(0, w.Len()-8):])
+	}
+}
@@ -1,10 +1,9 @@
 package util
 
-import "log"
-
+// IntLowHigh encodes n as b little-endian bytes (b is 1–4 in ESC/POS).
 func IntLowHigh(n int, b int) []byte {
-    if b < 1 || b > 4 {
-        log.Println("IntLowHigh: 1–4 bytes only")
+    if b < 1 {
+        return nil
     }
 
     out := make([]byte, b)